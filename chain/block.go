@@ -138,7 +138,7 @@ func (b *StatelessBlock) ID() ids.ID { return b.id }
 // verify checks the correctness of a block and then returns the
 // *versiondb.Database computed during execution.
 func (b *StatelessBlock) verify() (*StatelessBlock, *versiondb.Database, error) {
-	g := b.vm.Genesis()
+	g := b.vm.Rules(b.Tmstmp)
 
 	// Perform basic correctness checks before doing any expensive work
 	if len(b.Txs) == 0 {
@@ -182,7 +182,10 @@ func (b *StatelessBlock) verify() (*StatelessBlock, *versiondb.Database, error)
 	}
 	onAcceptDB := versiondb.New(parentState)
 
-	// Remove all expired spaces
+	// Renew subscribed spaces and remove all expired spaces
+	if err := RenewNext(g, onAcceptDB, parent.Tmstmp, b.Tmstmp, b.Price); err != nil {
+		return nil, nil, err
+	}
 	if err := ExpireNext(onAcceptDB, parent.Tmstmp, b.Tmstmp, b.vm.IsBootstrapped()); err != nil {
 		return nil, nil, err
 	}
@@ -206,7 +209,9 @@ func (b *StatelessBlock) verify() (*StatelessBlock, *versiondb.Database, error)
 
 // implements "snowman.Block"
 func (b *StatelessBlock) Verify(ctx context.Context) error {
+	start := time.Now()
 	parent, onAcceptDB, err := b.verify()
+	b.vm.Metrics().verified(start, err)
 	if err != nil {
 		log.Debug("block verification failed", "blkID", b.ID(), "error", err)
 		return err
@@ -225,6 +230,7 @@ func (b *StatelessBlock) Verify(ctx context.Context) error {
 
 // implements "snowman.Block.choices.Decidable"
 func (b *StatelessBlock) Accept(ctx context.Context) error {
+	start := time.Now()
 	if err := b.onAcceptDB.Commit(); err != nil {
 		return err
 	}
@@ -235,6 +241,7 @@ func (b *StatelessBlock) Accept(ctx context.Context) error {
 	}
 	b.st = choices.Accepted
 	b.vm.Accepted(b)
+	b.vm.Metrics().accepted(start, b)
 	return nil
 }
 
@@ -242,6 +249,7 @@ func (b *StatelessBlock) Accept(ctx context.Context) error {
 func (b *StatelessBlock) Reject(ctx context.Context) error {
 	b.st = choices.Rejected
 	b.vm.Rejected(b)
+	b.vm.Metrics().rejected()
 	return nil
 }
 
@@ -13,6 +13,7 @@ import (
 	"github.com/ava-labs/avalanchego/snow/choices"
 	"github.com/ethereum/go-ethereum/crypto"
 	gomock "github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 func TestBlock(t *testing.T) {
@@ -171,6 +172,12 @@ func createTestBlk(
 	ctrl := gomock.NewController(t)
 	vm := NewMockVM(ctrl)
 	vm.EXPECT().Genesis().Return(DefaultGenesis()).AnyTimes()
+	vm.EXPECT().Rules(gomock.Any()).Return(DefaultGenesis()).AnyTimes()
+	metrics, err := NewMetrics(prometheus.NewRegistry())
+	if err != nil {
+		t.Fatal(err)
+	}
+	vm.EXPECT().Metrics().Return(metrics).AnyTimes()
 	parentBlk.vm = vm
 	if err := parentBlk.init(); err != nil {
 		t.Fatal(err)
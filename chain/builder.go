@@ -4,6 +4,7 @@
 package chain
 
 import (
+	"errors"
 	"time"
 
 	"github.com/ava-labs/avalanchego/database/versiondb"
@@ -13,10 +14,11 @@ import (
 )
 
 func BuildBlock(vm VM, preferred ids.ID) (snowman.Block, error) {
-	g := vm.Genesis()
-
 	log.Debug("attempting block building")
-	nextTime := time.Now().Unix()
+	start := time.Now()
+	nextTime := start.Unix()
+	g := vm.Rules(nextTime)
+	metrics := vm.Metrics()
 	parent, err := vm.GetStatelessBlock(preferred)
 	if err != nil {
 		log.Debug("block building failed: couldn't get parent", "err", err)
@@ -40,7 +42,10 @@ func BuildBlock(vm VM, preferred ids.ID) (snowman.Block, error) {
 	}
 	vdb := versiondb.New(parentDB)
 
-	// Remove all expired spaces
+	// Renew subscribed spaces and remove all expired spaces
+	if err := RenewNext(g, vdb, parent.Tmstmp, b.Tmstmp, b.Price); err != nil {
+		return nil, err
+	}
 	if err := ExpireNext(vdb, parent.Tmstmp, b.Tmstmp, true); err != nil {
 		return nil, err
 	}
@@ -59,20 +64,24 @@ func BuildBlock(vm VM, preferred ids.ID) (snowman.Block, error) {
 
 	for mempool.Len() > 0 {
 		next, price := mempool.PopMax()
+		metrics.considered()
 		if price < b.Price {
 			mempool.Add(next)
+			metrics.skipped(SkipPrice)
 			log.Debug("skipping tx: too low price", "block price", b.Price, "tx price", price)
 			break
 		}
 		nextLoad := next.LoadUnits(g)
 		if units+nextLoad > g.MaxBlockSize {
 			unusableTxs = append(unusableTxs, next)
+			metrics.skipped(SkipSize)
 			log.Debug("skipping tx: too large", "block size", units, "tx load", nextLoad)
 			continue // could be txs that fit that are smaller
 		}
 		// Verify that changes pass
 		tvdb := versiondb.New(vdb)
 		if err := next.Execute(g, tvdb, b, context); err != nil {
+			metrics.skipped(SkipExecution)
 			log.Debug("skipping tx: failed verification", "err", err)
 			continue
 		}
@@ -94,7 +103,15 @@ func BuildBlock(vm VM, preferred ids.ID) (snowman.Block, error) {
 	_, _, err = b.verify()
 	if err != nil {
 		log.Debug("block building failed: failed verification", "err", err)
+		// Only a missing surplus fee resolves on its own (the block cost falls
+		// as time passes or txs paying more arrive), so only those txs are
+		// retried. They are dropped by [Mempool.Prune] once their block ID
+		// leaves the lookback window, which bounds the retries.
+		if errors.Is(err, ErrInsufficientSurplus) {
+			unusableTxs = append(unusableTxs, b.Txs...)
+		}
 		return nil, err
 	}
+	metrics.built(start, len(b.Txs), units)
 	return b, nil
 }
@@ -135,6 +135,20 @@ func TestClaimTx(t *testing.T) {
 	if err := ExpireNext(db, 0, ClaimReward*10, true); err != nil {
 		t.Fatal(err)
 	}
+	counts, err := KeyCounts(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if counts["pruning"] != 4 {
+		t.Fatalf("expected 4 pruning keys but got %d", counts["pruning"])
+	}
+	backlog, err := PruningBacklog(db, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if backlog != 4 {
+		t.Fatalf("expected backlog of 4 but got %d", backlog)
+	}
 	pruned, err := PruneNext(db, 100)
 	if err != nil {
 		t.Fatal(err)
@@ -142,6 +156,13 @@ func TestClaimTx(t *testing.T) {
 	if pruned != 4 {
 		t.Fatalf("expected to prune 4 but got %d", pruned)
 	}
+	backlog, err = PruningBacklog(db, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if backlog != 0 {
+		t.Fatalf("expected empty backlog but got %d", backlog)
+	}
 	_, exists, err := GetSpaceInfo(db, []byte("foo"))
 	if err != nil {
 		t.Fatalf("failed to get space info %v", err)
@@ -38,6 +38,9 @@ func init() {
 		c.RegisterType(&CustomAllocation{}),
 		c.RegisterType(&Airdrop{}),
 		c.RegisterType(&Genesis{}),
+		// Types added after launch must be registered last
+		c.RegisterType(&SubscribeTx{}),
+		c.RegisterType(&Subscription{}),
 		codecManager.RegisterCodec(codecVersion, c),
 	)
 	if errs.Errored() {
@@ -41,17 +41,34 @@ func verifySpace(s string, t *TransactionContext) (*SpaceInfo, error) {
 }
 
 func updateSpace(s string, t *TransactionContext, timeRemaining uint64, i *SpaceInfo) error {
-	newTimeRemaining := timeRemaining / i.Units
-	i.Updated = t.BlockTime
 	lastExpiry := i.Expiry
-	i.Expiry = t.BlockTime + newTimeRemaining
+	rescaleSpace(i, t.BlockTime, timeRemaining)
 	return PutSpaceInfo(t.Database, []byte(s), i, lastExpiry)
 }
 
+// rescaleSpace spreads [timeRemaining] (seconds * units) across the current
+// [Units] of [i], starting at [now].
+func rescaleSpace(i *SpaceInfo, now uint64, timeRemaining uint64) {
+	i.Updated = now
+	i.Expiry = now + timeRemaining/i.Units
+}
+
 func valueUnits(g *Genesis, size uint64) uint64 {
 	return size/g.ValueUnitSize + 1
 }
 
+// valueExpiryUnits is the number of [SpaceInfo.Units] a value of [size]
+// consumes.
+func valueExpiryUnits(g *Genesis, size uint64) uint64 {
+	return valueUnits(g, size) / g.ValueExpiryDiscount
+}
+
+// lifelineExtension is the number of seconds [units] of lifeline add to a
+// space with [spaceUnits].
+func lifelineExtension(g *Genesis, units uint64, spaceUnits uint64) uint64 {
+	return (g.ClaimReward * units) / spaceUnits
+}
+
 func valueHash(v []byte) string {
 	h := common.BytesToHash(crypto.Keccak256(v)).Hex()
 	return strings.ToLower(h)
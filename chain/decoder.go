@@ -22,6 +22,9 @@ const (
 	Move     = "move"
 	Transfer = "transfer"
 
+	// Gated (see [gatedTxs])
+	Subscribe = "subscribe"
+
 	// Non-user created event
 	Reward = "reward"
 )
@@ -33,6 +36,8 @@ type Input struct {
 	Value []byte         `json:"value"`
 	To    common.Address `json:"to"`
 	Units uint64         `json:"units"`
+	// Budget is only used by [Subscribe]
+	Budget uint64 `json:"budget,omitempty"`
 }
 
 func (i *Input) Decode() (UnsignedTransaction, error) {
@@ -73,6 +78,13 @@ func (i *Input) Decode() (UnsignedTransaction, error) {
 			To:     i.To,
 			Units:  i.Units,
 		}, nil
+	case Subscribe:
+		return &SubscribeTx{
+			BaseTx: &BaseTx{},
+			Space:  i.Space,
+			Units:  i.Units,
+			Budget: i.Budget,
+		}, nil
 	default:
 		return nil, ErrInvalidType
 	}
@@ -87,11 +99,12 @@ const (
 	tdBlockID = "blockID"
 	tdPrice   = "price"
 
-	tdSpace = "space"
-	tdKey   = "key"
-	tdValue = "value"
-	tdUnits = "units"
-	tdTo    = "to"
+	tdSpace  = "space"
+	tdKey    = "key"
+	tdValue  = "value"
+	tdUnits  = "units"
+	tdTo     = "to"
+	tdBudget = "budget"
 )
 
 func parseUint64Message(td *tdata.TypedData, k string) (uint64, error) {
@@ -193,6 +206,20 @@ func ParseTypedData(td *tdata.TypedData) (UnsignedTransaction, error) {
 			return nil, err
 		}
 		return &TransferTx{BaseTx: bTx, To: common.HexToAddress(to), Units: units}, nil
+	case Subscribe:
+		space, ok := td.Message[tdSpace].(string)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrTypedDataKeyMissing, tdSpace)
+		}
+		units, err := parseUint64Message(td, tdUnits)
+		if err != nil {
+			return nil, err
+		}
+		budget, err := parseUint64Message(td, tdBudget)
+		if err != nil {
+			return nil, err
+		}
+		return &SubscribeTx{BaseTx: bTx, Space: space, Units: units, Budget: budget}, nil
 	default:
 		return nil, ErrInvalidType
 	}
@@ -9,8 +9,10 @@ import (
 
 var (
 	// Genesis Correctness
-	ErrInvalidMagic     = errors.New("invalid magic")
-	ErrInvalidBlockRate = errors.New("invalid block rate")
+	ErrInvalidMagic       = errors.New("invalid magic")
+	ErrInvalidBlockRate   = errors.New("invalid block rate")
+	ErrInvalidBaseTxUnits = errors.New("invalid base tx units")
+	ErrInvalidUpgrade     = errors.New("invalid upgrade")
 
 	// Block Correctness
 	ErrTimestampTooEarly      = errors.New("block timestamp too early")
@@ -28,6 +30,7 @@ var (
 	ErrInsufficientPrice   = errors.New("insufficient price")
 	ErrInvalidType         = errors.New("invalid tx type")
 	ErrTypedDataKeyMissing = errors.New("typed data key missing")
+	ErrTxNotEnabled        = errors.New("tx type not enabled")
 
 	// Execution Correctness
 	ErrValueEmpty      = errors.New("value empty")
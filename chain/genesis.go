@@ -77,6 +77,9 @@ type Genesis struct {
 	CustomAllocation []*CustomAllocation `serialize:"true" json:"customAllocation"`
 	AirdropHash      string              `serialize:"true" json:"airdropHash"`
 	AirdropUnits     uint64              `serialize:"true" json:"airdropUnits"`
+
+	// Gated transaction types activated by [Upgrades]
+	enabledTxs map[string]struct{}
 }
 
 func DefaultGenesis() *Genesis {
@@ -125,9 +128,14 @@ func (g *Genesis) Verify() error {
 	if g.Magic == 0 {
 		return ErrInvalidMagic
 	}
-	if g.TargetBlockRate == 0 {
+	if g.TargetBlockRate <= 0 {
 		return ErrInvalidBlockRate
 	}
+	// [BaseTxUnits] is the smallest number of fee units of a tx (prices are
+	// derived by dividing by it)
+	if g.BaseTxUnits == 0 {
+		return ErrInvalidBaseTxUnits
+	}
 	return nil
 }
 
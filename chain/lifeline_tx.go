@@ -47,7 +47,7 @@ func (l *LifelineTx) Execute(t *TransactionContext) error {
 	}
 	// Lifeline spread across all units
 	lastExpiry := i.Expiry
-	i.Expiry += (g.ClaimReward * l.Units) / i.Units
+	i.Expiry += lifelineExtension(g, l.Units, i.Units)
 	return PutSpaceInfo(t.Database, []byte(l.Space), i, lastExpiry)
 }
 
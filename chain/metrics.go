@@ -0,0 +1,127 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"time"
+
+	"github.com/ava-labs/avalanchego/utils/wrappers"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Reasons a transaction was not included by [BuildBlock]
+const (
+	SkipPrice     = "price"
+	SkipSize      = "size"
+	SkipExecution = "execution"
+)
+
+// Metrics tracks block production, verification, and acceptance.
+type Metrics struct {
+	txsConsidered prometheus.Counter
+	txsSkipped    *prometheus.CounterVec
+	txsIncluded   prometheus.Counter
+	txsAccepted   *prometheus.CounterVec
+
+	blocksBuilt    prometheus.Counter
+	blocksFailed   prometheus.Counter
+	blocksRejected prometheus.Counter
+	blockUnits     prometheus.Histogram
+
+	buildDuration  prometheus.Histogram
+	verifyDuration prometheus.Histogram
+	acceptDuration prometheus.Histogram
+}
+
+func NewMetrics(r prometheus.Registerer) (*Metrics, error) {
+	m := &Metrics{
+		txsConsidered: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "build_txs_considered",
+			Help: "Number of mempool txs considered during block building",
+		}),
+		txsSkipped: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "build_txs_skipped",
+			Help: "Number of mempool txs not included during block building",
+		}, []string{"reason"}),
+		txsIncluded: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "build_txs_included",
+			Help: "Number of txs included in built blocks",
+		}),
+		txsAccepted: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "txs_accepted",
+			Help: "Number of accepted txs",
+		}, []string{"type"}),
+		blocksBuilt: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "blocks_built",
+			Help: "Number of blocks built",
+		}),
+		blocksFailed: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "blocks_verify_failed",
+			Help: "Number of blocks that failed verification",
+		}),
+		blocksRejected: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "blocks_rejected",
+			Help: "Number of rejected blocks",
+		}),
+		blockUnits: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "build_block_units",
+			Help:    "Load units included in built blocks",
+			Buckets: prometheus.LinearBuckets(0, 25, 11),
+		}),
+		buildDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name: "build_block_duration",
+			Help: "Time spent building blocks (seconds)",
+		}),
+		verifyDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name: "verify_block_duration",
+			Help: "Time spent verifying blocks (seconds)",
+		}),
+		acceptDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name: "accept_block_duration",
+			Help: "Time spent accepting blocks (seconds)",
+		}),
+	}
+	errs := wrappers.Errs{}
+	errs.Add(
+		r.Register(m.txsConsidered),
+		r.Register(m.txsSkipped),
+		r.Register(m.txsIncluded),
+		r.Register(m.txsAccepted),
+		r.Register(m.blocksBuilt),
+		r.Register(m.blocksFailed),
+		r.Register(m.blocksRejected),
+		r.Register(m.blockUnits),
+		r.Register(m.buildDuration),
+		r.Register(m.verifyDuration),
+		r.Register(m.acceptDuration),
+	)
+	return m, errs.Err
+}
+
+func (m *Metrics) considered() { m.txsConsidered.Inc() }
+
+func (m *Metrics) skipped(reason string) { m.txsSkipped.WithLabelValues(reason).Inc() }
+
+func (m *Metrics) built(start time.Time, txs int, units uint64) {
+	m.buildDuration.Observe(time.Since(start).Seconds())
+	m.blocksBuilt.Inc()
+	m.txsIncluded.Add(float64(txs))
+	m.blockUnits.Observe(float64(units))
+}
+
+func (m *Metrics) verified(start time.Time, err error) {
+	m.verifyDuration.Observe(time.Since(start).Seconds())
+	if err != nil {
+		m.blocksFailed.Inc()
+	}
+}
+
+func (m *Metrics) accepted(start time.Time, b *StatelessBlock) {
+	m.acceptDuration.Observe(time.Since(start).Seconds())
+	for _, tx := range b.Txs {
+		m.txsAccepted.WithLabelValues(tx.UnsignedTransaction.Activity().Typ).Inc()
+	}
+}
+
+func (m *Metrics) rejected() { m.blocksRejected.Inc() }
@@ -50,6 +50,11 @@ func (m *MoveTx) Execute(c *TransactionContext) error {
 	}
 	i.Owner = m.To
 
+	// Refund any renewal budget to the previous owner
+	if _, err := CancelSubscription(c.Database, []byte(m.Space)); err != nil {
+		return err
+	}
+
 	// Update space
 	if err := MoveSpaceInfo(c.Database, c.Sender, []byte(m.Space), i); err != nil {
 		return err
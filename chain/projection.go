@@ -0,0 +1,128 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/database"
+)
+
+// ProjectedOp is a hypothetical operation on a space. [Typ] must be one of
+// [Set], [Delete], or [Lifeline].
+type ProjectedOp struct {
+	Typ string `serialize:"true" json:"type"`
+
+	// Key and Size are used by [Set] and [Delete]
+	Key  string `serialize:"true" json:"key,omitempty"`
+	Size uint64 `serialize:"true" json:"size,omitempty"`
+
+	// Units is used by [Lifeline]
+	Units uint64 `serialize:"true" json:"units,omitempty"`
+}
+
+type Projection struct {
+	Units  uint64 `serialize:"true" json:"units"`
+	Expiry uint64 `serialize:"true" json:"expiry"`
+
+	// Lifeline needed (after all ops) for the space to last until the target
+	// time (0 if no target was provided or it is already reached)
+	LifelineUnits    uint64 `serialize:"true" json:"lifelineUnits"`
+	LifelineFeeUnits uint64 `serialize:"true" json:"lifelineFeeUnits"`
+}
+
+// ProjectSpace applies [ops] (in order) to [space] as if they were all
+// executed at [now] and returns the resulting [Units] and [Expiry]. If
+// [target] is non-zero, it also returns the lifeline units needed for the
+// space to not expire before [target].
+//
+// The math mirrors [SetTx], [DeleteTx], and [LifelineTx] execution but
+// ownership is not checked.
+func ProjectSpace(
+	g *Genesis,
+	db database.KeyValueReader,
+	space string,
+	now uint64,
+	ops []*ProjectedOp,
+	target uint64,
+) (*Projection, error) {
+	i, has, err := GetSpaceInfo(db, []byte(space))
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, ErrSpaceMissing
+	}
+	if i.Expiry < now {
+		return nil, ErrSpaceExpired
+	}
+
+	// Track sizes of values modified by [ops] (nil if deleted)
+	sizes := map[string]*uint64{}
+	getSize := func(key string) (uint64, bool, error) {
+		if size, ok := sizes[key]; ok {
+			if size == nil {
+				return 0, false, nil
+			}
+			return *size, true, nil
+		}
+		v, exists, err := GetValueMeta(db, []byte(space), []byte(key))
+		if err != nil || !exists {
+			return 0, false, err
+		}
+		return v.Size, true, nil
+	}
+
+	for idx, op := range ops {
+		switch op.Typ {
+		case Set:
+			if op.Size > g.MaxValueSize {
+				return nil, fmt.Errorf("%w: op %d", ErrValueTooBig, idx)
+			}
+			size, exists, err := getSize(op.Key)
+			if err != nil {
+				return nil, err
+			}
+			timeRemaining := (i.Expiry - i.Updated) * i.Units
+			if exists {
+				i.Units -= valueExpiryUnits(g, size)
+			}
+			i.Units += valueExpiryUnits(g, op.Size)
+			rescaleSpace(i, now, timeRemaining)
+			newSize := op.Size
+			sizes[op.Key] = &newSize
+		case Delete:
+			size, exists, err := getSize(op.Key)
+			if err != nil {
+				return nil, err
+			}
+			if !exists {
+				return nil, fmt.Errorf("%w: op %d", ErrKeyMissing, idx)
+			}
+			timeRemaining := (i.Expiry - i.Updated) * i.Units
+			i.Units -= valueExpiryUnits(g, size)
+			rescaleSpace(i, now, timeRemaining)
+			sizes[op.Key] = nil
+		case Lifeline:
+			if op.Units == 0 {
+				return nil, fmt.Errorf("%w: op %d", ErrNonActionable, idx)
+			}
+			i.Expiry += lifelineExtension(g, op.Units, i.Units)
+		default:
+			return nil, fmt.Errorf("%w: op %d has type %q", ErrInvalidType, idx, op.Typ)
+		}
+	}
+
+	p := &Projection{Units: i.Units, Expiry: i.Expiry}
+	if target > i.Expiry {
+		// Round up so that the space lasts at least until [target]
+		p.LifelineUnits = ((target-i.Expiry)*i.Units + g.ClaimReward - 1) / g.ClaimReward
+		p.LifelineFeeUnits = (&LifelineTx{
+			BaseTx: &BaseTx{},
+			Space:  space,
+			Units:  p.LifelineUnits,
+		}).FeeUnits(g)
+	}
+	return p, nil
+}
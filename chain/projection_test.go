@@ -0,0 +1,92 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestProjectSpace(t *testing.T) {
+	t.Parallel()
+
+	db := memdb.New()
+	defer db.Close()
+
+	g := DefaultGenesis()
+	sender := common.Address{1}
+	execute := func(utx UnsignedTransaction, blockTime uint64) {
+		if err := utx.Execute(&TransactionContext{
+			Genesis:   g,
+			Database:  db,
+			BlockTime: blockTime,
+			TxID:      ids.GenerateTestID(),
+			Sender:    sender,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	execute(&ClaimTx{BaseTx: &BaseTx{}, Space: "foo"}, 1)
+	execute(&SetTx{BaseTx: &BaseTx{}, Space: "foo", Key: "a", Value: bytes.Repeat([]byte{1}, 10*1024)}, 5)
+
+	if _, err := ProjectSpace(g, db, "bar", 10, nil, 0); !errors.Is(err, ErrSpaceMissing) {
+		t.Fatalf("expected %v, got %v", ErrSpaceMissing, err)
+	}
+	if _, err := ProjectSpace(g, db, "foo", 10, []*ProjectedOp{{Typ: Delete, Key: "b"}}, 0); !errors.Is(err, ErrKeyMissing) {
+		t.Fatalf("expected %v, got %v", ErrKeyMissing, err)
+	}
+	if _, err := ProjectSpace(g, db, "foo", 10, []*ProjectedOp{{Typ: Claim}}, 0); !errors.Is(err, ErrInvalidType) {
+		t.Fatalf("expected %v, got %v", ErrInvalidType, err)
+	}
+
+	ops := []*ProjectedOp{
+		{Typ: Set, Key: "b", Size: 50 * 1024},
+		{Typ: Set, Key: "a", Size: 20 * 1024},
+		{Typ: Delete, Key: "b"},
+		{Typ: Lifeline, Units: 3},
+	}
+	target := uint64(1 << 40)
+	p, err := ProjectSpace(g, db, "foo", 10, ops, target)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Projection must match actual execution
+	execute(&SetTx{BaseTx: &BaseTx{}, Space: "foo", Key: "b", Value: bytes.Repeat([]byte{1}, 50*1024)}, 10)
+	execute(&SetTx{BaseTx: &BaseTx{}, Space: "foo", Key: "a", Value: bytes.Repeat([]byte{1}, 20*1024)}, 10)
+	execute(&DeleteTx{BaseTx: &BaseTx{}, Space: "foo", Key: "b"}, 10)
+	execute(&LifelineTx{BaseTx: &BaseTx{}, Space: "foo", Units: 3}, 10)
+	i, _, err := GetSpaceInfo(db, []byte("foo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Units != i.Units || p.Expiry != i.Expiry {
+		t.Fatalf("projected units=%d expiry=%d, got units=%d expiry=%d", p.Units, p.Expiry, i.Units, i.Expiry)
+	}
+
+	// Lifeline units must be enough to reach target
+	if p.LifelineUnits == 0 || p.LifelineFeeUnits == 0 {
+		t.Fatalf("expected lifeline to be required: %+v", p)
+	}
+	execute(&LifelineTx{BaseTx: &BaseTx{}, Space: "foo", Units: p.LifelineUnits}, 10)
+	i, _, err = GetSpaceInfo(db, []byte("foo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if i.Expiry < target {
+		t.Fatalf("expiry %d before target %d", i.Expiry, target)
+	}
+	p, err = ProjectSpace(g, db, "foo", 10, nil, target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.LifelineUnits != 0 {
+		t.Fatalf("expected no lifeline to be required, got %d", p.LifelineUnits)
+	}
+}
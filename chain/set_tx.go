@@ -79,12 +79,12 @@ func (s *SetTx) Execute(t *TransactionContext) error {
 	}
 	timeRemaining := (i.Expiry - i.Updated) * i.Units
 	if exists {
-		i.Units -= valueUnits(g, v.Size) / g.ValueExpiryDiscount
+		i.Units -= valueExpiryUnits(g, v.Size)
 		nvmeta.Created = v.Created
 	} else {
 		nvmeta.Created = t.BlockTime
 	}
-	i.Units += valueUnits(g, valueSize) / g.ValueExpiryDiscount
+	i.Units += valueExpiryUnits(g, valueSize)
 	if err := PutSpaceKey(t.Database, []byte(s.Space), []byte(s.Key), nvmeta); err != nil {
 		return err
 	}
@@ -40,6 +40,8 @@ import (
 //   -> [owner]=> balance
 // 0x8/ (owned spaces)
 //   -> [owner]/[space]=> nil
+// 0x9/ (renewal subscriptions)
+//   -> [space]=> subscription
 
 const (
 	blockPrefix   = 0x0
@@ -51,12 +53,27 @@ const (
 	pruningPrefix = 0x6
 	balancePrefix = 0x7
 	ownedPrefix   = 0x8
+	subPrefix     = 0x9
 
 	shortIDLen = 20
 
 	linkedTxLRUSize = 512
 )
 
+// PrefixNames labels each storage prefix for reporting
+var PrefixNames = map[byte]string{
+	blockPrefix:   "block",
+	txPrefix:      "tx",
+	txValuePrefix: "txValue",
+	infoPrefix:    "info",
+	keyPrefix:     "key",
+	expiryPrefix:  "expiry",
+	pruningPrefix: "pruning",
+	balancePrefix: "balance",
+	ownedPrefix:   "owned",
+	subPrefix:     "subscription",
+}
+
 type CompactRange struct {
 	Start []byte
 	Limit []byte
@@ -73,10 +90,20 @@ var (
 		// Group expiry and pruning together
 		{[]byte{expiryPrefix, parser.ByteDelimiter}, []byte{balancePrefix, parser.ByteDelimiter}},
 		{[]byte{balancePrefix, parser.ByteDelimiter}, []byte{ownedPrefix, parser.ByteDelimiter}},
-		{[]byte{ownedPrefix, parser.ByteDelimiter}, []byte{ownedPrefix + 1, parser.ByteDelimiter}},
+		{[]byte{ownedPrefix, parser.ByteDelimiter}, []byte{subPrefix, parser.ByteDelimiter}},
+		{[]byte{subPrefix, parser.ByteDelimiter}, []byte{subPrefix + 1, parser.ByteDelimiter}},
 	}
 )
 
+// [subPrefix] + [delimiter] + [space]
+func PrefixSubscriptionKey(space []byte) (k []byte) {
+	k = make([]byte, 2+len(space))
+	k[0] = subPrefix
+	k[1] = parser.ByteDelimiter
+	copy(k[2:], space)
+	return
+}
+
 // [blockPrefix] + [delimiter] + [blockID]
 func PrefixBlockKey(blockID ids.ID) (k []byte) {
 	k = make([]byte, 2+len(blockID))
@@ -451,6 +478,11 @@ func ExpireNext(db database.Database, rparent int64, rcurrent int64, bootstrappe
 			return err
 		}
 
+		// Refund any remaining renewal budget
+		if _, err := CancelSubscription(db, space); err != nil {
+			return err
+		}
+
 		expired, rspc, err := extractSpecificTimeKey(curKey)
 		if err != nil {
 			return err
@@ -473,6 +505,73 @@ func ExpireNext(db database.Database, rparent int64, rcurrent int64, bootstrappe
 	return cursor.Error()
 }
 
+// RenewNext extends spaces in the range that [ExpireNext] is about to expire
+// using their renewal subscription (if any). Each renewal is equivalent to a
+// [LifelineTx] of the subscription's [Units] paid for at [price]. Spaces are
+// renewed until they no longer expire in the range or their budget is
+// exhausted.
+//
+// RenewNext must be called before [ExpireNext] with the same range.
+func RenewNext(g *Genesis, db database.Database, rparent int64, rcurrent int64, price uint64) error {
+	parent, current := uint64(rparent), uint64(rcurrent)
+	startKey := RangeTimeKey(expiryPrefix, parent)
+	endKey := RangeTimeKey(expiryPrefix, current)
+	cursor := db.NewIteratorWithStart(startKey)
+	spaces := [][]byte{}
+	for cursor.Next() {
+		// [expiryPrefix] + [delimiter] + [timestamp] + [delimiter] + [rawSpace]
+		if bytes.Compare(cursor.Key(), endKey) > 0 { // curKey > endKey; end search
+			break
+		}
+		// [owner] + [space]
+		expiryValue := cursor.Value()
+		space := make([]byte, len(expiryValue)-common.AddressLength)
+		copy(space, expiryValue[common.AddressLength:])
+		spaces = append(spaces, space)
+	}
+	err := cursor.Error()
+	cursor.Release()
+	if err != nil {
+		return err
+	}
+
+	for _, space := range spaces {
+		sub, exists, err := GetSubscription(db, space)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			continue
+		}
+		i, exists, err := GetSpaceInfo(db, space)
+		if err != nil {
+			return err
+		}
+		if !exists || i.Owner != sub.Owner {
+			continue
+		}
+		lastExpiry := i.Expiry
+		fee := (&LifelineTx{BaseTx: &BaseTx{}, Space: string(space), Units: sub.Units}).FeeUnits(g) * price
+		extension := lifelineExtension(g, sub.Units, i.Units)
+		for i.Expiry < current && sub.Budget >= fee && extension > 0 {
+			sub.Budget -= fee
+			i.Expiry += extension
+		}
+		if i.Expiry == lastExpiry {
+			log.Debug("unable to renew space", "space", string(space), "budget", sub.Budget, "fee", fee)
+			continue
+		}
+		if err := PutSubscription(db, space, sub); err != nil {
+			return err
+		}
+		if err := PutSpaceInfo(db, space, i, lastExpiry); err != nil {
+			return err
+		}
+		log.Debug("space renewed", "space", string(space), "expiry", i.Expiry, "budget", sub.Budget)
+	}
+	return nil
+}
+
 // PruneNext queries the keys that are currently marked with "pruningPrefix",
 // and clears them from the database.
 func PruneNext(db database.Database, limit int) (removals int, err error) {
@@ -506,6 +605,42 @@ func PruneNext(db database.Database, limit int) (removals int, err error) {
 	return removals, cursor.Error()
 }
 
+// PruningBacklog returns the number of raw spaces waiting to be cleared by
+// [PruneNext], counting at most [limit].
+func PruningBacklog(db database.Iteratee, limit int) (count int, err error) {
+	startKey := RangeTimeKey(pruningPrefix, 0)
+	endKey := RangeTimeKey(pruningPrefix, math.MaxInt64)
+	cursor := db.NewIteratorWithStart(startKey)
+	defer cursor.Release()
+	for cursor.Next() && count < limit {
+		if bytes.Compare(cursor.Key(), endKey) > 0 { // curKey > endKey; end search
+			break
+		}
+		count++
+	}
+	return count, cursor.Error()
+}
+
+// KeyCounts returns the number of keys stored under each prefix in
+// [PrefixNames].
+func KeyCounts(db database.Iteratee) (map[string]uint64, error) {
+	counts := make(map[string]uint64, len(PrefixNames))
+	for p, name := range PrefixNames {
+		cursor := db.NewIteratorWithPrefix([]byte{p, parser.ByteDelimiter})
+		count := uint64(0)
+		for cursor.Next() {
+			count++
+		}
+		err := cursor.Error()
+		cursor.Release()
+		if err != nil {
+			return nil, err
+		}
+		counts[name] = count
+	}
+	return counts, nil
+}
+
 // DB
 func HasSpace(db database.KeyValueReader, space []byte) (bool, error) {
 	// [infoPrefix] + [delimiter] + [space]
@@ -595,6 +730,46 @@ func MoveSpaceInfo(
 	return db.Put(k, ExpiryDataValue(i.Owner, space))
 }
 
+func GetSubscription(db database.KeyValueReader, space []byte) (*Subscription, bool, error) {
+	// [subPrefix] + [delimiter] + [space]
+	k := PrefixSubscriptionKey(space)
+	v, err := db.Get(k)
+	if errors.Is(err, database.ErrNotFound) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	sub := new(Subscription)
+	if _, err := Unmarshal(v, sub); err != nil {
+		return nil, false, err
+	}
+	return sub, true, nil
+}
+
+func PutSubscription(db database.KeyValueWriter, space []byte, sub *Subscription) error {
+	// [subPrefix] + [delimiter] + [space]
+	k := PrefixSubscriptionKey(space)
+	b, err := Marshal(sub)
+	if err != nil {
+		return err
+	}
+	return db.Put(k, b)
+}
+
+// CancelSubscription removes the subscription for [space] (if it exists) and
+// refunds its remaining budget to its owner.
+func CancelSubscription(db database.KeyValueReaderWriterDeleter, space []byte) (bool, error) {
+	sub, exists, err := GetSubscription(db, space)
+	if err != nil || !exists {
+		return false, err
+	}
+	if _, err := ModifyBalance(db, sub.Owner, true, sub.Budget); err != nil {
+		return false, err
+	}
+	return true, db.Delete(PrefixSubscriptionKey(space))
+}
+
 type ValueMeta struct {
 	Size uint64 `serialize:"true" json:"size"`
 	TxID ids.ID `serialize:"true" json:"txId"`
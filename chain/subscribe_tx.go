@@ -0,0 +1,104 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"strconv"
+
+	"github.com/ava-labs/spacesvm/parser"
+	"github.com/ava-labs/spacesvm/tdata"
+)
+
+var _ UnsignedTransaction = &SubscribeTx{}
+
+type SubscribeTx struct {
+	*BaseTx `serialize:"true" json:"baseTx"`
+
+	// Space is the namespace for the "SpaceInfo"
+	// whose owner can write and read value for the
+	// specific key space.
+	//
+	// The space must be ^[a-z0-9]{1,256}$.
+	Space string `serialize:"true" json:"space"`
+
+	// Units is the number of [ClaimReward] to extend the life of the [Space]
+	// by each time it is renewed. If [Units] is 0, the subscription is
+	// cancelled and the remaining budget is refunded.
+	Units uint64 `serialize:"true" json:"units"`
+
+	// Budget is added to the balance set aside to pay for renewals.
+	Budget uint64 `serialize:"true" json:"budget"`
+}
+
+func (s *SubscribeTx) Execute(t *TransactionContext) error {
+	if err := parser.CheckContents(s.Space); err != nil {
+		return err
+	}
+
+	// Verify space is owned by sender
+	if _, err := verifySpace(s.Space, t); err != nil {
+		return err
+	}
+
+	sub, exists, err := GetSubscription(t.Database, []byte(s.Space))
+	if err != nil {
+		return err
+	}
+	if s.Units == 0 {
+		if !exists || s.Budget > 0 {
+			return ErrNonActionable
+		}
+		_, err := CancelSubscription(t.Database, []byte(s.Space))
+		return err
+	}
+	if !exists {
+		if s.Budget == 0 {
+			return ErrNonActionable
+		}
+		sub = &Subscription{Owner: t.Sender}
+	}
+	if _, err := ModifyBalance(t.Database, t.Sender, false, s.Budget); err != nil {
+		return err
+	}
+	sub.Units = s.Units
+	sub.Budget += s.Budget
+	return PutSubscription(t.Database, []byte(s.Space), sub)
+}
+
+func (s *SubscribeTx) Copy() UnsignedTransaction {
+	return &SubscribeTx{
+		BaseTx: s.BaseTx.Copy(),
+		Space:  s.Space,
+		Units:  s.Units,
+		Budget: s.Budget,
+	}
+}
+
+func (s *SubscribeTx) TypedData() *tdata.TypedData {
+	return tdata.CreateTypedData(
+		s.Magic, Subscribe,
+		[]tdata.Type{
+			{Name: tdSpace, Type: tdString},
+			{Name: tdUnits, Type: tdUint64},
+			{Name: tdBudget, Type: tdUint64},
+			{Name: tdPrice, Type: tdUint64},
+			{Name: tdBlockID, Type: tdString},
+		},
+		tdata.TypedDataMessage{
+			tdSpace:   s.Space,
+			tdUnits:   strconv.FormatUint(s.Units, 10),
+			tdBudget:  strconv.FormatUint(s.Budget, 10),
+			tdPrice:   strconv.FormatUint(s.Price, 10),
+			tdBlockID: s.BlockID.String(),
+		},
+	)
+}
+
+func (s *SubscribeTx) Activity() *Activity {
+	return &Activity{
+		Typ:   Subscribe,
+		Space: s.Space,
+		Units: s.Units,
+	}
+}
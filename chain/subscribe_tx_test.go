@@ -0,0 +1,226 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestSubscribeTx(t *testing.T) {
+	t.Parallel()
+
+	db := memdb.New()
+	defer db.Close()
+
+	g := DefaultGenesis()
+	sender, sender2 := common.Address{1}, common.Address{2}
+	if err := SetBalance(db, sender, 1000); err != nil {
+		t.Fatal(err)
+	}
+	tt := []struct {
+		utx     UnsignedTransaction
+		sender  common.Address
+		err     error
+		balance uint64
+		sub     *Subscription
+	}{
+		{ // invalid when space info is missing
+			utx:    &SubscribeTx{BaseTx: &BaseTx{}, Space: "foo", Units: 1, Budget: 10},
+			sender: sender,
+			err:    ErrSpaceMissing,
+		},
+		{ // successful claim
+			utx:     &ClaimTx{BaseTx: &BaseTx{}, Space: "foo"},
+			sender:  sender,
+			balance: 1000,
+		},
+		{ // invalid when not owner
+			utx:    &SubscribeTx{BaseTx: &BaseTx{}, Space: "foo", Units: 1, Budget: 10},
+			sender: sender2,
+			err:    ErrUnauthorized,
+		},
+		{ // invalid when nothing to cancel
+			utx:    &SubscribeTx{BaseTx: &BaseTx{}, Space: "foo"},
+			sender: sender,
+			err:    ErrNonActionable,
+		},
+		{ // invalid when no budget
+			utx:    &SubscribeTx{BaseTx: &BaseTx{}, Space: "foo", Units: 1},
+			sender: sender,
+			err:    ErrNonActionable,
+		},
+		{ // invalid when budget exceeds balance
+			utx:    &SubscribeTx{BaseTx: &BaseTx{}, Space: "foo", Units: 1, Budget: 1001},
+			sender: sender,
+			err:    ErrInvalidBalance,
+		},
+		{ // successful subscription
+			utx:     &SubscribeTx{BaseTx: &BaseTx{}, Space: "foo", Units: 1, Budget: 100},
+			sender:  sender,
+			balance: 900,
+			sub:     &Subscription{Owner: sender, Units: 1, Budget: 100},
+		},
+		{ // successful top-up with new units
+			utx:     &SubscribeTx{BaseTx: &BaseTx{}, Space: "foo", Units: 5, Budget: 50},
+			sender:  sender,
+			balance: 850,
+			sub:     &Subscription{Owner: sender, Units: 5, Budget: 150},
+		},
+		{ // successful cancel
+			utx:     &SubscribeTx{BaseTx: &BaseTx{}, Space: "foo"},
+			sender:  sender,
+			balance: 1000,
+		},
+	}
+	for i, tv := range tt {
+		tc := &TransactionContext{
+			Genesis:   g,
+			Database:  db,
+			BlockTime: 1,
+			TxID:      ids.Empty,
+			Sender:    tv.sender,
+		}
+		err := tv.utx.Execute(tc)
+		if !errors.Is(err, tv.err) {
+			t.Fatalf("#%d: tx.Execute err expected %v, got %v", i, tv.err, err)
+		}
+		if err != nil {
+			continue
+		}
+		bal, err := GetBalance(db, sender)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bal != tv.balance {
+			t.Fatalf("#%d: expected balance %d, got %d", i, tv.balance, bal)
+		}
+		sub, exists, err := GetSubscription(db, []byte("foo"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tv.sub == nil {
+			if exists {
+				t.Fatalf("#%d: unexpected subscription %+v", i, sub)
+			}
+			continue
+		}
+		if !exists || *sub != *tv.sub {
+			t.Fatalf("#%d: expected subscription %+v, got %+v", i, tv.sub, sub)
+		}
+	}
+
+	// Budget is refunded to the previous owner when a space is moved
+	tc := &TransactionContext{Genesis: g, Database: db, BlockTime: 1, Sender: sender}
+	if err := (&SubscribeTx{BaseTx: &BaseTx{}, Space: "foo", Units: 1, Budget: 100}).Execute(tc); err != nil {
+		t.Fatal(err)
+	}
+	if err := (&MoveTx{BaseTx: &BaseTx{}, Space: "foo", To: sender2}).Execute(tc); err != nil {
+		t.Fatal(err)
+	}
+	if _, exists, err := GetSubscription(db, []byte("foo")); err != nil || exists {
+		t.Fatalf("subscription should have been removed (err=%v)", err)
+	}
+	if bal, err := GetBalance(db, sender); err != nil || bal != 1000 {
+		t.Fatalf("expected balance 1000, got %d (err=%v)", bal, err)
+	}
+}
+
+func TestRenewNext(t *testing.T) {
+	t.Parallel()
+
+	db := memdb.New()
+	defer db.Close()
+
+	g := DefaultGenesis()
+	sender := common.Address{1}
+	if err := SetBalance(db, sender, 1_000_000); err != nil {
+		t.Fatal(err)
+	}
+	tc := &TransactionContext{
+		Genesis:   g,
+		Database:  db,
+		BlockTime: 1,
+		TxID:      ids.Empty,
+		Sender:    sender,
+	}
+	fee := (&LifelineTx{BaseTx: &BaseTx{}, Space: "foo", Units: 10}).FeeUnits(g)
+	budget := fee + 1
+	for _, utx := range []UnsignedTransaction{
+		&ClaimTx{BaseTx: &BaseTx{}, Space: "foo"},
+		&ClaimTx{BaseTx: &BaseTx{}, Space: "bar"},
+		&ClaimTx{BaseTx: &BaseTx{}, Space: "baz"},
+		// Enough budget for a single renewal
+		&SubscribeTx{BaseTx: &BaseTx{}, Space: "foo", Units: 10, Budget: budget},
+		// Not enough budget for any renewal
+		&SubscribeTx{BaseTx: &BaseTx{}, Space: "bar", Units: 10, Budget: 1},
+	} {
+		if err := utx.Execute(tc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	foo, _, err := GetSpaceInfo(db, []byte("foo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Nothing should happen before expiry
+	if err := RenewNext(g, db, 0, int64(foo.Expiry), 1); err != nil {
+		t.Fatal(err)
+	}
+	sub, _, err := GetSubscription(db, []byte("foo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sub.Budget != budget {
+		t.Fatalf("unexpected renewal: %+v", sub)
+	}
+
+	// Renew and expire
+	current := int64(foo.Expiry) + 1
+	if err := RenewNext(g, db, 0, current, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := ExpireNext(db, 0, current, true); err != nil {
+		t.Fatal(err)
+	}
+	renewed, exists, err := GetSpaceInfo(db, []byte("foo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !exists {
+		t.Fatal("subscribed space expired")
+	}
+	if expected := foo.Expiry + lifelineExtension(g, 10, foo.Units); renewed.Expiry != expected {
+		t.Fatalf("expected expiry %d, got %d", expected, renewed.Expiry)
+	}
+	sub, _, err = GetSubscription(db, []byte("foo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sub.Budget != 1 {
+		t.Fatalf("expected budget 1, got %d", sub.Budget)
+	}
+	for _, space := range []string{"bar", "baz"} {
+		if _, exists, err := GetSpaceInfo(db, []byte(space)); err != nil || exists {
+			t.Fatalf("%s should have expired (err=%v)", space, err)
+		}
+	}
+
+	// Budget of expired spaces should be refunded
+	if _, exists, err := GetSubscription(db, []byte("bar")); err != nil || exists {
+		t.Fatalf("subscription should have been removed (err=%v)", err)
+	}
+	bal, err := GetBalance(db, sender)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bal != 1_000_000-budget {
+		t.Fatalf("expected balance %d, got %d", 1_000_000-budget, bal)
+	}
+}
@@ -0,0 +1,18 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Subscription is a standing order to renew a space from a pre-funded
+// [Budget] before it expires (see [RenewNext]).
+type Subscription struct {
+	Owner common.Address `serialize:"true" json:"owner"`
+	// Units is the number of lifeline units added per renewal
+	Units uint64 `serialize:"true" json:"units"`
+	// Budget is the balance remaining to pay for renewals
+	Budget uint64 `serialize:"true" json:"budget"`
+}
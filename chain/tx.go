@@ -4,6 +4,8 @@
 package chain
 
 import (
+	"fmt"
+
 	"github.com/ava-labs/avalanchego/database"
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/spacesvm/tdata"
@@ -43,6 +45,30 @@ func DigestHash(utx UnsignedTransaction) ([]byte, error) {
 }
 
 func (t *Transaction) Init(g *Genesis) error {
+	if err := t.init(); err != nil {
+		return err
+	}
+
+	// Derive sender
+	pk, err := DeriveSender(t.digestHash, t.Signature)
+	if err != nil {
+		return err
+	}
+	t.sender = crypto.PubkeyToAddress(*pk)
+	return nil
+}
+
+// InitSimulated is like [Init] but uses [sender] instead of deriving it from
+// [Signature]. It should only be used to simulate unsigned transactions.
+func (t *Transaction) InitSimulated(sender common.Address) error {
+	if err := t.init(); err != nil {
+		return err
+	}
+	t.sender = sender
+	return nil
+}
+
+func (t *Transaction) init() error {
 	stx, err := Marshal(t)
 	if err != nil {
 		return err
@@ -62,13 +88,6 @@ func (t *Transaction) Init(g *Genesis) error {
 	}
 	t.digestHash = dh
 
-	// Derive sender
-	pk, err := DeriveSender(t.digestHash, t.Signature)
-	if err != nil {
-		return err
-	}
-	t.sender = crypto.PubkeyToAddress(*pk)
-
 	t.size = uint64(len(t.Bytes()))
 	return nil
 }
@@ -87,6 +106,9 @@ func (t *Transaction) Execute(g *Genesis, db database.Database, blk *StatelessBl
 	if err := t.UnsignedTransaction.ExecuteBase(g); err != nil {
 		return err
 	}
+	if typ := t.UnsignedTransaction.Activity().Typ; !g.TxEnabled(typ) {
+		return fmt.Errorf("%w: %s", ErrTxNotEnabled, typ)
+	}
 	if !context.RecentBlockIDs.Contains(t.GetBlockID()) {
 		// Hash must be recent to be any good
 		// Should not happen beause of mempool cleanup
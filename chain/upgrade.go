@@ -0,0 +1,174 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// gatedTxs are transaction types that were introduced after launch. They are
+// rejected until an [Upgrade] lists them in [EnabledTxs].
+var gatedTxs = map[string]struct{}{
+	Subscribe: {},
+}
+
+// GatedTxs returns the (sorted) transaction types that must be enabled by an
+// [Upgrade].
+func GatedTxs() []string {
+	typs := make([]string, 0, len(gatedTxs))
+	for typ := range gatedTxs {
+		typs = append(typs, typ)
+	}
+	sort.Strings(typs)
+	return typs
+}
+
+// Upgrade is a set of rule changes that take effect for all blocks with a
+// timestamp >= [Timestamp]. Any field that is not set retains the value that
+// was in effect prior to the upgrade.
+type Upgrade struct {
+	Timestamp int64 `json:"timestamp"`
+
+	// Tx params
+	BaseTxUnits *uint64 `json:"baseTxUnits,omitempty"`
+
+	// SetTx params
+	ValueUnitSize       *uint64 `json:"valueUnitSize,omitempty"`
+	MaxValueSize        *uint64 `json:"maxValueSize,omitempty"`
+	ValueExpiryDiscount *uint64 `json:"valueExpiryDiscount,omitempty"`
+
+	// Claim Params
+	ClaimLoadMultiplier         *uint64 `json:"claimLoadMultiplier,omitempty"`
+	MinClaimFee                 *uint64 `json:"minClaimFee,omitempty"`
+	SpaceDesirabilityMultiplier *uint64 `json:"spaceDesirabilityMultiplier,omitempty"`
+
+	// Lifeline Params
+	SpaceRenewalDiscount *uint64 `json:"spaceRenewalDiscount,omitempty"`
+
+	// Mining Reward (% of min required fee)
+	LotteryRewardMultipler *uint64 `json:"lotteryRewardMultipler,omitempty"`
+
+	// Fee Mechanism Params
+	MinPrice         *uint64 `json:"minPrice,omitempty"`
+	TargetBlockRate  *int64  `json:"targetBlockRate,omitempty"`
+	TargetBlockSize  *uint64 `json:"targetBlockSize,omitempty"`
+	MaxBlockSize     *uint64 `json:"maxBlockSize,omitempty"`
+	BlockCostEnabled *bool   `json:"blockCostEnabled,omitempty"`
+
+	// EnabledTxs activates gated transaction types
+	EnabledTxs []string `json:"enabledTxs,omitempty"`
+}
+
+// Upgrades is the network upgrade schedule provided to the VM as
+// [upgradeBytes]. Upgrades must be sorted by [Timestamp].
+type Upgrades struct {
+	Upgrades []*Upgrade `json:"upgrades"`
+}
+
+func ParseUpgrades(b []byte) (*Upgrades, error) {
+	u := new(Upgrades)
+	if len(b) == 0 {
+		return u, nil
+	}
+	if err := json.Unmarshal(b, u); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// Verify ensures that the schedule is ordered and that applying it to [g]
+// never produces invalid rules.
+func (u *Upgrades) Verify(g *Genesis) error {
+	last := int64(-1)
+	for i, up := range u.Upgrades {
+		if up == nil {
+			return fmt.Errorf("%w: upgrade %d is empty", ErrInvalidUpgrade, i)
+		}
+		if up.Timestamp <= last {
+			return fmt.Errorf("%w: upgrade %d is not after previous upgrade", ErrInvalidUpgrade, i)
+		}
+		last = up.Timestamp
+		for _, typ := range up.EnabledTxs {
+			if _, ok := gatedTxs[typ]; !ok {
+				return fmt.Errorf("%w: %s cannot be enabled", ErrInvalidUpgrade, typ)
+			}
+		}
+		r := u.Rules(g, up.Timestamp)
+		switch {
+		case r.BaseTxUnits == 0:
+			return fmt.Errorf("%w: upgrade %d sets zero base tx units", ErrInvalidUpgrade, i)
+		case r.ValueUnitSize == 0:
+			return fmt.Errorf("%w: upgrade %d sets zero value unit size", ErrInvalidUpgrade, i)
+		case r.ValueExpiryDiscount == 0:
+			return fmt.Errorf("%w: upgrade %d sets zero value expiry discount", ErrInvalidUpgrade, i)
+		case r.SpaceRenewalDiscount == 0:
+			return fmt.Errorf("%w: upgrade %d sets zero space renewal discount", ErrInvalidUpgrade, i)
+		case r.TargetBlockRate <= 0:
+			return fmt.Errorf("%w: upgrade %d sets non-positive block rate", ErrInvalidUpgrade, i)
+		}
+	}
+	return nil
+}
+
+// Rules returns a copy of [g] with all upgrades activated at or before
+// [t] applied. If no upgrades are active, [g] is returned as-is.
+func (u *Upgrades) Rules(g *Genesis, t int64) *Genesis {
+	if u == nil || len(u.Upgrades) == 0 || u.Upgrades[0].Timestamp > t {
+		return g
+	}
+	r := *g
+	r.enabledTxs = map[string]struct{}{}
+	for typ := range g.enabledTxs {
+		r.enabledTxs[typ] = struct{}{}
+	}
+	for _, up := range u.Upgrades {
+		if up.Timestamp > t {
+			break
+		}
+		up.apply(&r)
+	}
+	return &r
+}
+
+func (up *Upgrade) apply(r *Genesis) {
+	setUint64(&r.BaseTxUnits, up.BaseTxUnits)
+	setUint64(&r.ValueUnitSize, up.ValueUnitSize)
+	setUint64(&r.MaxValueSize, up.MaxValueSize)
+	setUint64(&r.ValueExpiryDiscount, up.ValueExpiryDiscount)
+	setUint64(&r.ClaimLoadMultiplier, up.ClaimLoadMultiplier)
+	setUint64(&r.MinClaimFee, up.MinClaimFee)
+	setUint64(&r.SpaceDesirabilityMultiplier, up.SpaceDesirabilityMultiplier)
+	setUint64(&r.SpaceRenewalDiscount, up.SpaceRenewalDiscount)
+	setUint64(&r.LotteryRewardMultipler, up.LotteryRewardMultipler)
+	setUint64(&r.MinPrice, up.MinPrice)
+	setUint64(&r.TargetBlockSize, up.TargetBlockSize)
+	setUint64(&r.MaxBlockSize, up.MaxBlockSize)
+	if up.TargetBlockRate != nil {
+		r.TargetBlockRate = *up.TargetBlockRate
+	}
+	if up.BlockCostEnabled != nil {
+		r.BlockCostEnabled = *up.BlockCostEnabled
+	}
+	for _, typ := range up.EnabledTxs {
+		r.enabledTxs[typ] = struct{}{}
+	}
+}
+
+func setUint64(dst *uint64, src *uint64) {
+	if src != nil {
+		*dst = *src
+	}
+}
+
+// TxEnabled returns true if transactions of type [typ] can be executed under
+// the rules described by [g].
+func (g *Genesis) TxEnabled(typ string) bool {
+	if _, gated := gatedTxs[typ]; !gated {
+		return true
+	}
+	_, ok := g.enabledTxs[typ]
+	return ok
+}
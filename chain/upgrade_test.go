@@ -0,0 +1,100 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestUpgradesRules(t *testing.T) {
+	t.Parallel()
+
+	g := DefaultGenesis()
+	maxValueSize, maxBlockSize, minPrice := uint64(10), uint64(500), uint64(7)
+	u, err := ParseUpgrades([]byte(`{"upgrades":[
+		{"timestamp":10,"maxValueSize":10,"minPrice":7},
+		{"timestamp":20,"maxBlockSize":500}
+	]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := u.Verify(g); err != nil {
+		t.Fatal(err)
+	}
+
+	if r := u.Rules(g, 9); r != g {
+		t.Fatal("expected genesis before first upgrade")
+	}
+	r := u.Rules(g, 10)
+	if r.MaxValueSize != maxValueSize || r.MinPrice != minPrice {
+		t.Fatalf("first upgrade not applied: %+v", r)
+	}
+	if r.MaxBlockSize != g.MaxBlockSize {
+		t.Fatalf("second upgrade applied early: %d", r.MaxBlockSize)
+	}
+	r = u.Rules(g, 25)
+	if r.MaxValueSize != maxValueSize || r.MaxBlockSize != maxBlockSize {
+		t.Fatalf("upgrades not applied: %+v", r)
+	}
+
+	// Genesis must not be modified
+	if g.MaxValueSize == maxValueSize || g.MaxBlockSize == maxBlockSize {
+		t.Fatal("genesis was modified")
+	}
+}
+
+func TestUpgradesVerify(t *testing.T) {
+	t.Parallel()
+
+	g := DefaultGenesis()
+	for i, b := range []string{
+		`{"upgrades":[{"timestamp":10},{"timestamp":10}]}`,
+		`{"upgrades":[{"timestamp":10},{"timestamp":5}]}`,
+		`{"upgrades":[{"timestamp":10,"valueExpiryDiscount":0}]}`,
+		`{"upgrades":[{"timestamp":10,"targetBlockRate":0}]}`,
+		`{"upgrades":[{"timestamp":10,"targetBlockRate":-1}]}`,
+		`{"upgrades":[{"timestamp":10,"baseTxUnits":0}]}`,
+		`{"upgrades":[{"timestamp":10,"enabledTxs":["claim"]}]}`,
+		`{"upgrades":[null]}`,
+	} {
+		u, err := ParseUpgrades([]byte(b))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := u.Verify(g); !errors.Is(err, ErrInvalidUpgrade) {
+			t.Fatalf("#%d: expected %v, got %v", i, ErrInvalidUpgrade, err)
+		}
+	}
+
+	u, err := ParseUpgrades(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := u.Verify(g); err != nil {
+		t.Fatal(err)
+	}
+	if !u.Rules(g, 100).TxEnabled(Claim) {
+		t.Fatal("ungated tx should be enabled")
+	}
+}
+
+func TestUpgradesEnabledTxs(t *testing.T) {
+	t.Parallel()
+
+	g := DefaultGenesis()
+	u, err := ParseUpgrades([]byte(`{"upgrades":[{"timestamp":10,"enabledTxs":["subscribe"]}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := u.Verify(g); err != nil {
+		t.Fatal(err)
+	}
+	if u.Rules(g, 9).TxEnabled(Subscribe) {
+		t.Fatal("gated tx should not be enabled before upgrade")
+	}
+	if !u.Rules(g, 10).TxEnabled(Subscribe) {
+		t.Fatal("gated tx should be enabled after upgrade")
+	}
+}
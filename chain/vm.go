@@ -23,9 +23,11 @@ type Context struct {
 
 type VM interface {
 	Genesis() *Genesis
+	Rules(currentTime int64) *Genesis
 	IsBootstrapped() bool
 	State() database.Database
 	Mempool() Mempool
+	Metrics() *Metrics
 	GetStatelessBlock(ids.ID) (*StatelessBlock, error)
 	ExecutionContext(currentTime int64, parent *StatelessBlock) (*Context, error)
 	Verified(*StatelessBlock)
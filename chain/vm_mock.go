@@ -123,6 +123,20 @@ func (mr *MockVMMockRecorder) Mempool() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Mempool", reflect.TypeOf((*MockVM)(nil).Mempool))
 }
 
+// Metrics mocks base method.
+func (m *MockVM) Metrics() *Metrics {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Metrics")
+	ret0, _ := ret[0].(*Metrics)
+	return ret0
+}
+
+// Metrics indicates an expected call of Metrics.
+func (mr *MockVMMockRecorder) Metrics() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Metrics", reflect.TypeOf((*MockVM)(nil).Metrics))
+}
+
 // Rejected mocks base method.
 func (m *MockVM) Rejected(arg0 *StatelessBlock) {
 	m.ctrl.T.Helper()
@@ -135,6 +149,20 @@ func (mr *MockVMMockRecorder) Rejected(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rejected", reflect.TypeOf((*MockVM)(nil).Rejected), arg0)
 }
 
+// Rules mocks base method.
+func (m *MockVM) Rules(currentTime int64) *Genesis {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Rules", currentTime)
+	ret0, _ := ret[0].(*Genesis)
+	return ret0
+}
+
+// Rules indicates an expected call of Rules.
+func (mr *MockVMMockRecorder) Rules(currentTime interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rules", reflect.TypeOf((*MockVM)(nil).Rules), currentTime)
+}
+
 // State mocks base method.
 func (m *MockVM) State() database.Database {
 	m.ctrl.T.Helper()
@@ -15,7 +15,6 @@ import (
 	"github.com/ava-labs/avalanchego/utils/rpc"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
-	"github.com/fatih/color"
 
 	"github.com/ava-labs/spacesvm/chain"
 	"github.com/ava-labs/spacesvm/parser"
@@ -32,6 +31,8 @@ type Client interface {
 
 	// Returns the VM genesis.
 	Genesis(ctx context.Context) (*chain.Genesis, error)
+	// Returns the genesis params with all currently active upgrades applied.
+	Rules(ctx context.Context) (*chain.Genesis, error)
 	// Accepted fetches the ID of the last accepted block.
 	Accepted(ctx context.Context) (ids.ID, error)
 
@@ -41,8 +42,22 @@ type Client interface {
 	Info(ctx context.Context, space string) (*chain.SpaceInfo, []*chain.KeyValueMeta, error)
 	// Balance returns the balance of an account
 	Balance(ctx context.Context, addr common.Address) (bal uint64, err error)
+	// ProjectSpace returns the units and expiry of a space after the
+	// hypothetical [ops] and the lifeline units needed to reach [target] (unix
+	// time, optional).
+	ProjectSpace(
+		ctx context.Context,
+		space string,
+		ops []*chain.ProjectedOp,
+		target uint64,
+	) (*chain.Projection, error)
+	// Subscription returns the renewal subscription of a space (if any).
+	Subscription(ctx context.Context, space string) (*chain.Subscription, bool, error)
 	// Resolve returns the value associated with a path
 	Resolve(ctx context.Context, path string) (exists bool, value []byte, valueMeta *chain.ValueMeta, err error)
+	// HasKeys returns which of [keys] have a value in [space] (at most 1024
+	// keys per call)
+	HasKeys(ctx context.Context, space string, keys []string) ([]bool, error)
 
 	// Requests the suggested price and cost from VM.
 	SuggestedRawFee(ctx context.Context) (uint64, uint64, error)
@@ -50,11 +65,27 @@ type Client interface {
 	IssueRawTx(ctx context.Context, d []byte) (ids.ID, error)
 
 	// Requests the suggested price and cost from VM, returns the input as
-	// TypedData.
-	SuggestedFee(ctx context.Context, i *chain.Input) (*tdata.TypedData, uint64, error)
+	// TypedData and the unix time after which it may be rejected (because its
+	// block ID is no longer recent).
+	SuggestedFee(ctx context.Context, i *chain.Input) (td *tdata.TypedData, totalCost uint64, validUntil int64, err error)
+	// Returns the suggested price and total cost of the input at several
+	// inclusion speeds (fastest first).
+	EstimateFee(ctx context.Context, i *chain.Input) ([]*vm.FeeEstimate, error)
+	// Returns the prices, costs, and load units of up to [blocks] recently
+	// accepted blocks (newest first).
+	FeeHistory(ctx context.Context, blocks int) ([]*vm.FeeHistoryEntry, error)
 	// Issues a human-readable transaction and returns the transaction ID.
 	IssueTx(ctx context.Context, td *tdata.TypedData, sig []byte) (ids.ID, error)
 
+	// Executes the transaction against the preferred block without issuing it.
+	// [sig] may be nil to simulate an unsigned transaction from [sender].
+	SimulateTx(
+		ctx context.Context,
+		td *tdata.TypedData,
+		sig []byte,
+		sender common.Address,
+	) (*vm.Simulation, error)
+
 	// Checks the status of the transaction, and returns "true" if confirmed.
 	HasTx(ctx context.Context, id ids.ID) (bool, error)
 	// Polls the transactions until its status is confirmed.
@@ -116,6 +147,17 @@ func (cli *client) Genesis(ctx context.Context) (*chain.Genesis, error) {
 	return resp.Genesis, err
 }
 
+func (cli *client) Rules(ctx context.Context) (*chain.Genesis, error) {
+	resp := new(vm.RulesReply)
+	err := cli.req.SendRequest(
+		ctx,
+		"spacesvm.rules",
+		nil,
+		resp,
+	)
+	return resp.Rules, err
+}
+
 func (cli *client) Claimed(ctx context.Context, space string) (bool, error) {
 	resp := new(vm.ClaimedReply)
 	if err := cli.req.SendRequest(
@@ -150,7 +192,6 @@ func (cli *client) Accepted(ctx context.Context) (ids.ID, error) {
 		nil,
 		resp,
 	); err != nil {
-		color.Red("failed to get curr block %v", err)
 		return ids.ID{}, err
 	}
 	return resp.BlockID, nil
@@ -195,7 +236,7 @@ func (cli *client) HasTx(ctx context.Context, txID ids.ID) (bool, error) {
 	return resp.Accepted, nil
 }
 
-func (cli *client) SuggestedFee(ctx context.Context, i *chain.Input) (*tdata.TypedData, uint64, error) {
+func (cli *client) SuggestedFee(ctx context.Context, i *chain.Input) (*tdata.TypedData, uint64, int64, error) {
 	resp := new(vm.SuggestedFeeReply)
 	if err := cli.req.SendRequest(
 		ctx,
@@ -203,9 +244,35 @@ func (cli *client) SuggestedFee(ctx context.Context, i *chain.Input) (*tdata.Typ
 		&vm.SuggestedFeeArgs{Input: i},
 		resp,
 	); err != nil {
-		return nil, 0, err
+		return nil, 0, 0, err
 	}
-	return resp.TypedData, resp.TotalCost, nil
+	return resp.TypedData, resp.TotalCost, resp.ValidUntil, nil
+}
+
+func (cli *client) EstimateFee(ctx context.Context, i *chain.Input) ([]*vm.FeeEstimate, error) {
+	resp := new(vm.EstimateFeeReply)
+	if err := cli.req.SendRequest(
+		ctx,
+		"spacesvm.estimateFee",
+		&vm.EstimateFeeArgs{Input: i},
+		resp,
+	); err != nil {
+		return nil, err
+	}
+	return resp.Estimates, nil
+}
+
+func (cli *client) FeeHistory(ctx context.Context, blocks int) ([]*vm.FeeHistoryEntry, error) {
+	resp := new(vm.FeeHistoryReply)
+	if err := cli.req.SendRequest(
+		ctx,
+		"spacesvm.feeHistory",
+		&vm.FeeHistoryArgs{Blocks: blocks},
+		resp,
+	); err != nil {
+		return nil, err
+	}
+	return resp.History, nil
 }
 
 func (cli *client) IssueTx(ctx context.Context, td *tdata.TypedData, sig []byte) (ids.ID, error) {
@@ -222,6 +289,28 @@ func (cli *client) IssueTx(ctx context.Context, td *tdata.TypedData, sig []byte)
 	return resp.TxID, nil
 }
 
+func (cli *client) SimulateTx(
+	ctx context.Context,
+	td *tdata.TypedData,
+	sig []byte,
+	sender common.Address,
+) (*vm.Simulation, error) {
+	args := &vm.SimulateTxArgs{TypedData: td, Signature: sig}
+	if len(sig) == 0 {
+		args.Sender = &sender
+	}
+	resp := new(vm.SimulateTxReply)
+	if err := cli.req.SendRequest(
+		ctx,
+		"spacesvm.simulateTx",
+		args,
+		resp,
+	); err != nil {
+		return nil, err
+	}
+	return resp.Simulation, nil
+}
+
 func (cli *client) PollTx(ctx context.Context, txID ids.ID) (confirmed bool, err error) {
 done:
 	for ctx.Err() == nil {
@@ -233,7 +322,7 @@ done:
 
 		confirmed, err := cli.HasTx(ctx, txID)
 		if err != nil {
-			color.Red("polling transaction failed %v", err)
+			// Transient errors are retried until [ctx] is done
 			continue
 		}
 		if confirmed {
@@ -243,6 +332,37 @@ done:
 	return false, ctx.Err()
 }
 
+func (cli *client) ProjectSpace(
+	ctx context.Context,
+	space string,
+	ops []*chain.ProjectedOp,
+	target uint64,
+) (*chain.Projection, error) {
+	resp := new(vm.ProjectSpaceReply)
+	if err := cli.req.SendRequest(
+		ctx,
+		"spacesvm.projectSpace",
+		&vm.ProjectSpaceArgs{Space: space, Ops: ops, Target: target},
+		resp,
+	); err != nil {
+		return nil, err
+	}
+	return resp.Projection, nil
+}
+
+func (cli *client) Subscription(ctx context.Context, space string) (*chain.Subscription, bool, error) {
+	resp := new(vm.SubscriptionReply)
+	if err := cli.req.SendRequest(
+		ctx,
+		"spacesvm.subscription",
+		&vm.SubscriptionArgs{Space: space},
+		resp,
+	); err != nil {
+		return nil, false, err
+	}
+	return resp.Subscription, resp.Exists, nil
+}
+
 func (cli *client) Resolve(ctx context.Context, path string) (bool, []byte, *chain.ValueMeta, error) {
 	resp := new(vm.ResolveReply)
 	if err := cli.req.SendRequest(
@@ -272,6 +392,25 @@ func (cli *client) Resolve(ctx context.Context, path string) (bool, []byte, *cha
 	return true, resp.Value, resp.ValueMeta, nil
 }
 
+func (cli *client) HasKeys(ctx context.Context, space string, keys []string) ([]bool, error) {
+	resp := new(vm.HasKeysReply)
+	if err := cli.req.SendRequest(
+		ctx,
+		"spacesvm.hasKeys",
+		&vm.HasKeysArgs{
+			Space: space,
+			Keys:  keys,
+		},
+		resp,
+	); err != nil {
+		return nil, err
+	}
+	if len(resp.Exists) != len(keys) {
+		return nil, fmt.Errorf("%w: expected %d results, got %d", ErrInvalidResponse, len(keys), len(resp.Exists))
+	}
+	return resp.Exists, nil
+}
+
 func (cli *client) IssueTxHR(ctx context.Context, d []byte, sig []byte) (ids.ID, error) {
 	return ids.ID{}, errors.New("not implemented")
 }
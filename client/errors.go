@@ -5,4 +5,17 @@ package client
 
 import "errors"
 
-var ErrIntegrityFailure = errors.New("received file that does not match hash")
+var (
+	ErrFeeTooHigh       = errors.New("fee exceeds maximum")
+	ErrIntegrityFailure = errors.New("received file that does not match hash")
+	ErrInvalidResponse  = errors.New("invalid response")
+	ErrNotConfirmed     = errors.New("transaction not confirmed")
+	ErrRemoteSigner     = errors.New("remote signer failed")
+	ErrSignerMismatch   = errors.New("signature is not from expected address")
+	ErrUnauthorized     = errors.New("unauthorized")
+	ErrNotSealed        = errors.New("value is not sealed")
+	ErrInvalidSealed    = errors.New("invalid sealed value")
+	ErrNotRecipient     = errors.New("key is not a recipient")
+	ErrNoRecipients     = errors.New("no recipients")
+	ErrInvalidPublicKey = errors.New("invalid public key")
+)
@@ -5,63 +5,39 @@ package client
 
 import (
 	"context"
-	"crypto/ecdsa"
-	"encoding/json"
 	"fmt"
-	"time"
 
 	"github.com/ava-labs/avalanchego/ids"
-	"github.com/ethereum/go-ethereum/crypto"
-	"github.com/fatih/color"
 
 	"github.com/ava-labs/spacesvm/chain"
 	"github.com/ava-labs/spacesvm/tdata"
 )
 
-func PPInfo(info *chain.SpaceInfo) {
-	expiry := time.Unix(int64(info.Expiry), 0)
-	color.Cyan(
-		"raw space %s: units=%d expiry=%v (%v remaining)",
-		info.RawSpace, info.Units, expiry, time.Until(expiry),
-	)
-}
-
-func PPActivity(a []*chain.Activity) error {
-	if len(a) == 0 {
-		color.Cyan("no recent activity")
-	}
-	for _, item := range a {
-		b, err := json.Marshal(item)
-		if err != nil {
-			return err
-		}
-		color.Cyan(string(b))
-	}
-	return nil
-}
-
 // Signs and issues the transaction (node construction).
 func SignIssueTx(
 	ctx context.Context,
 	cli Client,
 	input *chain.Input,
-	priv *ecdsa.PrivateKey,
+	signer Signer,
 	opts ...OpOption,
 ) (txID ids.ID, cost uint64, err error) {
 	ret := &Op{}
 	ret.applyOpts(opts)
 
-	td, txCost, err := cli.SuggestedFee(ctx, input)
+	td, txCost, _, err := cli.SuggestedFee(ctx, input)
 	if err != nil {
 		return ids.Empty, 0, err
 	}
+	if err := ret.checkFee(txCost); err != nil {
+		return ids.Empty, 0, err
+	}
 
 	dh, err := tdata.DigestHash(td)
 	if err != nil {
 		return ids.Empty, 0, fmt.Errorf("%w: failed to compute digest hash", err)
 	}
 
-	sig, err := chain.Sign(dh, priv)
+	sig, err := signer.SignDigest(ctx, dh)
 	if err != nil {
 		return ids.Empty, 0, err
 	}
@@ -71,7 +47,7 @@ func SignIssueTx(
 		return ids.Empty, 0, err
 	}
 
-	if err := handleConfirmation(ctx, ret, cli, txID, priv); err != nil {
+	if err := handleConfirmation(ctx, ret, cli, txID); err != nil {
 		return ids.Empty, 0, err
 	}
 	return txID, txCost, nil
@@ -82,13 +58,13 @@ func SignIssueRawTx(
 	ctx context.Context,
 	cli Client,
 	utx chain.UnsignedTransaction,
-	priv *ecdsa.PrivateKey,
+	signer Signer,
 	opts ...OpOption,
 ) (txID ids.ID, cost uint64, err error) {
 	ret := &Op{}
 	ret.applyOpts(opts)
 
-	g, err := cli.Genesis(ctx)
+	g, err := cli.Rules(ctx)
 	if err != nil {
 		return ids.Empty, 0, err
 	}
@@ -106,13 +82,17 @@ func SignIssueRawTx(
 	utx.SetBlockID(la)
 	utx.SetMagic(g.Magic)
 	utx.SetPrice(price + blockCost/utx.FeeUnits(g))
+	txCost := utx.GetPrice() * utx.FeeUnits(g)
+	if err := ret.checkFee(txCost); err != nil {
+		return ids.Empty, 0, err
+	}
 
 	dh, err := chain.DigestHash(utx)
 	if err != nil {
 		return ids.Empty, 0, err
 	}
 
-	sig, err := chain.Sign(dh, priv)
+	sig, err := signer.SignDigest(ctx, dh)
 	if err != nil {
 		return ids.Empty, 0, err
 	}
@@ -122,63 +102,34 @@ func SignIssueRawTx(
 		return ids.Empty, 0, err
 	}
 
-	color.Yellow(
-		"issuing tx %s (fee units=%d, load units=%d, price=%d, blkID=%s)",
-		tx.ID(), tx.FeeUnits(g), tx.LoadUnits(g), tx.GetPrice(), tx.GetBlockID(),
-	)
 	txID, err = cli.IssueRawTx(ctx, tx.Bytes())
 	if err != nil {
 		return ids.Empty, 0, err
 	}
 
-	if err := handleConfirmation(ctx, ret, cli, txID, priv); err != nil {
+	if err := handleConfirmation(ctx, ret, cli, txID); err != nil {
 		return ids.Empty, 0, err
 	}
-	return txID, utx.GetPrice() * utx.FeeUnits(g), nil
+	return txID, txCost, nil
 }
 
-func handleConfirmation(
-	ctx context.Context, ret *Op, cli Client,
-	txID ids.ID, priv *ecdsa.PrivateKey,
-) error {
-	if ret.pollTx {
-		color.Yellow("issued transaction %s (now polling)", txID)
-		confirmed, err := cli.PollTx(ctx, txID)
-		if err != nil {
-			return err
-		}
-		if !confirmed {
-			color.Yellow("transaction %s not confirmed", txID)
-		} else {
-			color.Yellow("transaction %s confirmed", txID)
-		}
-	}
-
-	if len(ret.space) > 0 {
-		info, _, err := cli.Info(ctx, ret.space)
-		if err != nil {
-			color.Red("cannot get space info %v", err)
-			return err
-		}
-		PPInfo(info)
-	}
-
-	if ret.balance {
-		addr := crypto.PubkeyToAddress(priv.PublicKey)
-		b, err := cli.Balance(ctx, addr)
-		if err != nil {
-			return err
-		}
-		color.Cyan("Address=%s Balance=%d", addr, b)
+func handleConfirmation(ctx context.Context, ret *Op, cli Client, txID ids.ID) error {
+	if !ret.pollTx {
+		return nil
+	}
+	confirmed, err := cli.PollTx(ctx, txID)
+	if err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrNotConfirmed, txID, err)
+	}
+	if !confirmed {
+		return fmt.Errorf("%w: %s", ErrNotConfirmed, txID)
 	}
-
 	return nil
 }
 
 type Op struct {
-	pollTx  bool
-	space   string
-	balance bool
+	pollTx bool
+	maxFee uint64
 }
 
 type OpOption func(*Op)
@@ -189,16 +140,19 @@ func (op *Op) applyOpts(opts []OpOption) {
 	}
 }
 
+func (op *Op) checkFee(cost uint64) error {
+	if op.maxFee > 0 && cost > op.maxFee {
+		return fmt.Errorf("%w: cost %d exceeds %d", ErrFeeTooHigh, cost, op.maxFee)
+	}
+	return nil
+}
+
 // "true" to poll transaction for its confirmation.
 func WithPollTx() OpOption {
 	return func(op *Op) { op.pollTx = true }
 }
 
-// Non-empty to print out space information.
-func WithInfo(space string) OpOption {
-	return func(op *Op) { op.space = space }
-}
-
-func WithBalance() OpOption {
-	return func(op *Op) { op.balance = true }
+// Refuses to sign transactions that cost more than [fee] (0 is unlimited).
+func WithMaxFee(fee uint64) OpOption {
+	return func(op *Op) { op.maxFee = fee }
 }
@@ -0,0 +1,194 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package client
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ecies"
+)
+
+const (
+	// SealVersion is the version of the [Sealed] values written by [Seal].
+	SealVersion = 1
+
+	// KeySize is the size of the symmetric keys (AES-256) of sealed values
+	// and files.
+	KeySize = 32
+)
+
+// Recipient can decrypt a sealed value (or file) with the private key of
+// [Address].
+type Recipient struct {
+	Address common.Address `json:"address"`
+	// Symmetric key encrypted to the public key of [Address] (ECIES)
+	Key hexutil.Bytes `json:"key"`
+}
+
+// Sealed is a value encrypted (with AES-256-GCM) to a set of [Recipient]s.
+type Sealed struct {
+	Version    uint64       `json:"version"`
+	Recipients []*Recipient `json:"recipients"`
+	Nonce      []byte       `json:"nonce"`
+	Ciphertext []byte       `json:"ciphertext"`
+}
+
+// sealedValue is the JSON encoding of a value written by [Seal].
+type sealedValue struct {
+	Sealed *Sealed `json:"sealed"`
+}
+
+// NewKey returns a random symmetric key.
+func NewKey() ([]byte, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// NewAEAD returns the AES-256-GCM cipher of [key].
+func NewAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("%w: key must be %d bytes", ErrInvalidSealed, KeySize)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// WrapKey encrypts [key] to each of [recipients].
+func WrapKey(key []byte, recipients []*ecdsa.PublicKey) ([]*Recipient, error) {
+	if len(recipients) == 0 {
+		return nil, ErrNoRecipients
+	}
+	wrapped := make([]*Recipient, len(recipients))
+	for i, pub := range recipients {
+		ct, err := ecies.Encrypt(rand.Reader, ecies.ImportECDSAPublic(pub), key, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		wrapped[i] = &Recipient{Address: crypto.PubkeyToAddress(*pub), Key: ct}
+	}
+	return wrapped, nil
+}
+
+// UnwrapKey decrypts the key wrapped for the address of [priv].
+func UnwrapKey(recipients []*Recipient, priv *ecdsa.PrivateKey) ([]byte, error) {
+	addr := crypto.PubkeyToAddress(priv.PublicKey)
+	for _, r := range recipients {
+		if r.Address != addr {
+			continue
+		}
+		key, err := ecies.ImportECDSA(priv).Decrypt(r.Key, nil, nil)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidSealed, err)
+		}
+		if len(key) != KeySize {
+			return nil, fmt.Errorf("%w: key must be %d bytes", ErrInvalidSealed, KeySize)
+		}
+		return key, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrNotRecipient, addr)
+}
+
+// Seal encrypts [value] with a new key that is wrapped to each of
+// [recipients] and returns the encoded [Sealed] value (which can be written
+// with a [chain.SetTx] and decrypted with [Open]).
+func Seal(value []byte, recipients ...*ecdsa.PublicKey) ([]byte, error) {
+	key, err := NewKey()
+	if err != nil {
+		return nil, err
+	}
+	wrapped, err := WrapKey(key, recipients)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := NewAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return json.Marshal(&sealedValue{&Sealed{
+		Version:    SealVersion,
+		Recipients: wrapped,
+		Nonce:      nonce,
+		Ciphertext: aead.Seal(nil, nonce, value, nil),
+	}})
+}
+
+// IsSealed returns true if [value] was written by [Seal].
+func IsSealed(value []byte) bool {
+	_, err := parseSealed(value)
+	return err == nil
+}
+
+// Open decrypts a value written by [Seal] with [priv] (which must be one of
+// its recipients).
+func Open(value []byte, priv *ecdsa.PrivateKey) ([]byte, error) {
+	s, err := parseSealed(value)
+	if err != nil {
+		return nil, err
+	}
+	key, err := UnwrapKey(s.Recipients, priv)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := NewAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(s.Nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("%w: invalid nonce", ErrInvalidSealed)
+	}
+	b, err := aead.Open(nil, s.Nonce, s.Ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSealed, err)
+	}
+	return b, nil
+}
+
+func parseSealed(value []byte) (*Sealed, error) {
+	var v sealedValue
+	if err := json.Unmarshal(value, &v); err != nil || v.Sealed == nil {
+		return nil, ErrNotSealed
+	}
+	if v.Sealed.Version > SealVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidSealed, v.Sealed.Version)
+	}
+	return v.Sealed, nil
+}
+
+// ParsePublicKey parses a hex-encoded (compressed or uncompressed) secp256k1
+// public key.
+func ParsePublicKey(s string) (*ecdsa.PublicKey, error) {
+	b, err := hexutil.Decode("0x" + strings.TrimPrefix(s, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPublicKey, err)
+	}
+	var pub *ecdsa.PublicKey
+	if len(b) == 33 {
+		pub, err = crypto.DecompressPubkey(b)
+	} else {
+		pub, err = crypto.UnmarshalPubkey(b)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPublicKey, err)
+	}
+	return pub, nil
+}
@@ -0,0 +1,201 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/ava-labs/spacesvm/chain"
+	"github.com/ava-labs/spacesvm/keystore"
+)
+
+const maxSignerMessageSize = 64 * 1024
+
+// Signer signs transaction digests on behalf of a single address.
+type Signer interface {
+	Address() common.Address
+	// SignDigest returns the 65-byte [R || S || V] signature of [dh] (see
+	// [chain.Sign]).
+	SignDigest(ctx context.Context, dh []byte) ([]byte, error)
+}
+
+var (
+	_ Signer = &keySigner{}
+	_ Signer = &remoteSigner{}
+)
+
+type keySigner struct {
+	priv *ecdsa.PrivateKey
+	addr common.Address
+}
+
+// NewKeySigner returns a [Signer] that signs with an in-memory key.
+func NewKeySigner(priv *ecdsa.PrivateKey) Signer {
+	return &keySigner{priv: priv, addr: crypto.PubkeyToAddress(priv.PublicKey)}
+}
+
+// NewKeystoreSigner decrypts the key stored as [name] in [ks] and returns
+// a [Signer] for it.
+func NewKeystoreSigner(ks *keystore.Keystore, name string, passphrase string) (Signer, error) {
+	priv, err := ks.Load(name, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return NewKeySigner(priv), nil
+}
+
+func (s *keySigner) Address() common.Address { return s.addr }
+
+func (s *keySigner) SignDigest(_ context.Context, dh []byte) ([]byte, error) {
+	return chain.Sign(dh, s.priv)
+}
+
+// SignRequest is sent (as JSON) to a remote signer.
+type SignRequest struct {
+	Address common.Address `json:"address"`
+	Digest  hexutil.Bytes  `json:"digest"`
+}
+
+// SignResponse is returned (as JSON) by a remote signer. [Error] is
+// populated if the response status is not 200.
+type SignResponse struct {
+	Signature hexutil.Bytes `json:"signature,omitempty"`
+	Error     string        `json:"error,omitempty"`
+}
+
+type remoteSigner struct {
+	endpoint string
+	addr     common.Address
+	token    string
+	cli      *http.Client
+}
+
+// NewRemoteSigner returns a [Signer] that POSTs a [SignRequest] for [addr]
+// to [endpoint] (with [token] as a bearer token, unless empty) and expects a
+// [SignResponse]. Returned signatures are verified before they are used.
+func NewRemoteSigner(endpoint string, addr common.Address, token string, timeout time.Duration) Signer {
+	return &remoteSigner{
+		endpoint: endpoint,
+		addr:     addr,
+		token:    token,
+		cli:      &http.Client{Timeout: timeout},
+	}
+}
+
+func (s *remoteSigner) Address() common.Address { return s.addr }
+
+func (s *remoteSigner) SignDigest(ctx context.Context, dh []byte) ([]byte, error) {
+	b, err := json.Marshal(&SignRequest{Address: s.addr, Digest: dh})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if len(s.token) > 0 {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+	resp, err := s.cli.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrRemoteSigner, err)
+	}
+	defer resp.Body.Close()
+	var sr SignResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxSignerMessageSize)).Decode(&sr); err != nil {
+		return nil, fmt.Errorf("%w: invalid response (status %d): %v", ErrRemoteSigner, resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: status %d: %s", ErrRemoteSigner, resp.StatusCode, sr.Error)
+	}
+
+	// Don't trust the signer to have used the right key
+	pk, err := chain.DeriveSender(dh, sr.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrRemoteSigner, err)
+	}
+	if signer := crypto.PubkeyToAddress(*pk); signer != s.addr {
+		return nil, fmt.Errorf("%w: expected %s, got %s", ErrSignerMismatch, s.addr, signer)
+	}
+	return sr.Signature, nil
+}
+
+// SignPolicy decides whether the digest [dh] requested by [r] may be signed
+// (returning an error otherwise).
+type SignPolicy func(r *http.Request, dh []byte) error
+
+// BearerToken returns a [SignPolicy] that only allows requests with [token]
+// as their bearer token (like the admin API). An empty [token] allows
+// nothing.
+func BearerToken(token string) SignPolicy {
+	return func(r *http.Request, _ []byte) error {
+		provided := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if len(token) == 0 || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			return ErrUnauthorized
+		}
+		return nil
+	}
+}
+
+// NewSignerHandler serves [SignRequest]s for the address of [signer] that
+// [authorize] allows (every request is rejected if it is nil). It can be
+// used to implement a remote signer.
+//
+// Anyone that can reach the handler can spend the funds of [signer] within
+// what [authorize] allows, so it must never be exposed without a policy
+// that authenticates requests (like [BearerToken]).
+func NewSignerHandler(signer Signer, authorize SignPolicy) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reply := func(status int, resp *SignResponse) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(status)
+			_ = json.NewEncoder(w).Encode(resp)
+		}
+		if r.Method != http.MethodPost {
+			reply(http.StatusMethodNotAllowed, &SignResponse{Error: "method not allowed"})
+			return
+		}
+		var req SignRequest
+		if err := json.NewDecoder(io.LimitReader(r.Body, maxSignerMessageSize)).Decode(&req); err != nil {
+			reply(http.StatusBadRequest, &SignResponse{Error: err.Error()})
+			return
+		}
+		if len(req.Digest) != common.HashLength {
+			reply(http.StatusBadRequest, &SignResponse{Error: "digest must be 32 bytes"})
+			return
+		}
+		if authorize == nil {
+			reply(http.StatusUnauthorized, &SignResponse{Error: "no signing policy"})
+			return
+		}
+		if err := authorize(r, req.Digest); err != nil {
+			reply(http.StatusUnauthorized, &SignResponse{Error: err.Error()})
+			return
+		}
+		if req.Address != signer.Address() {
+			reply(http.StatusNotFound, &SignResponse{Error: fmt.Sprintf("unknown address %s", req.Address)})
+			return
+		}
+		sig, err := signer.SignDigest(r.Context(), req.Digest)
+		if err != nil {
+			reply(http.StatusInternalServerError, &SignResponse{Error: err.Error()})
+			return
+		}
+		reply(http.StatusOK, &SignResponse{Signature: sig})
+	})
+}
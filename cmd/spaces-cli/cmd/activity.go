@@ -5,8 +5,10 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 
+	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 
 	"github.com/ava-labs/spacesvm/client"
@@ -27,8 +29,13 @@ func activityFunc(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	if err := client.PPActivity(activity); err != nil {
-		return err
-	}
-	return nil
+	return printResult(activity, func() {
+		if len(activity) == 0 {
+			color.Cyan("no recent activity")
+		}
+		for _, item := range activity {
+			b, _ := json.Marshal(item)
+			color.Cyan(string(b))
+		}
+	})
 }
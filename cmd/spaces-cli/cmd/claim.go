@@ -8,8 +8,6 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/ethereum/go-ethereum/crypto"
-	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 
 	"github.com/ava-labs/spacesvm/chain"
@@ -35,7 +33,7 @@ COMMENT
 }
 
 func claimFunc(cmd *cobra.Command, args []string) error {
-	priv, err := crypto.LoadECDSA(privateKeyFile)
+	signer, err := loadSigner()
 	if err != nil {
 		return err
 	}
@@ -51,17 +49,11 @@ func claimFunc(cmd *cobra.Command, args []string) error {
 	}
 
 	cli := client.New(uri, requestTimeout)
-	opts := []client.OpOption{client.WithPollTx()}
-	if verbose {
-		opts = append(opts, client.WithInfo(space))
-		opts = append(opts, client.WithBalance())
-	}
-	if _, _, err := client.SignIssueRawTx(context.Background(), cli, utx, priv, opts...); err != nil {
+	res, err := issueTx(context.Background(), cli, utx, signer, space)
+	if err != nil {
 		return err
 	}
-
-	color.Green("claimed %s", space)
-	return nil
+	return printTx(res, signer, "claimed %s", space)
 }
 
 func getClaimOp(args []string) (space string, err error) {
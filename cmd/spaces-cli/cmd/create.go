@@ -5,48 +5,48 @@ package cmd
 
 import (
 	"errors"
-	"os"
 
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
+
+	"github.com/ava-labs/spacesvm/keystore"
 )
 
 var createCmd = &cobra.Command{
 	Use:   "create [options]",
-	Short: "Creates a new key in the default location",
+	Short: "Creates a new key in the keystore",
 	Long: `
-Creates a new key in the default location.
-It will error if the key file already exists.
+Creates a new key named --key (default "default") in the encrypted keystore.
+It will error if the key already exists.
 
 $ spaces-cli create
+$ spaces-cli create --key staging
 
 `,
 	RunE: createFunc,
 }
 
 func createFunc(cmd *cobra.Command, args []string) error {
-	if _, err := os.Stat(privateKeyFile); err == nil {
+	ks := keystore.New(keystoreDir)
+	if _, err := ks.Export(keyName); err == nil {
 		// Already found, remind the user they have it
-		priv, err := crypto.LoadECDSA(privateKeyFile)
-		if err != nil {
-			return err
-		}
-		color.Green("ABORTING!!! key for %s already exists at %s", crypto.PubkeyToAddress(priv.PublicKey), privateKeyFile)
-		return os.ErrExist
-	} else if !errors.Is(err, os.ErrNotExist) {
+		color.Green("ABORTING!!! key %s already exists at %s", keyName, ks.Path(keyName))
+		return keystore.ErrKeyExists
+	} else if !errors.Is(err, keystore.ErrKeyMissing) {
 		return err
 	}
 
-	// Generate new key and save to disk
-	// TODO: encrypt key
-	priv, err := crypto.GenerateKey()
+	// Generate new key and save it (encrypted) to disk
+	passphrase, err := readPassphrase(keyName, true)
 	if err != nil {
 		return err
 	}
-	if err := crypto.SaveECDSA(privateKeyFile, priv); err != nil {
+	priv, err := ks.Create(keyName, passphrase)
+	if err != nil {
 		return err
 	}
-	color.Green("created address %s and saved to %s", crypto.PubkeyToAddress(priv.PublicKey), privateKeyFile)
-	return nil
+	res := newKeyResult(keyName, priv, ks.Path(keyName))
+	return printResult(res, func() {
+		color.Green("created address %s and saved to %s", res.Address, res.Path)
+	})
 }
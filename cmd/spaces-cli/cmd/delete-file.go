@@ -7,7 +7,6 @@ import (
 	"context"
 	"fmt"
 
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 
@@ -17,12 +16,12 @@ import (
 
 var deleteFileCmd = &cobra.Command{
 	Use:   "delete-file [options] <space/key>",
-	Short: "Deletes all hashes reachable from root file identifier",
+	Short: "Deletes all hashes reachable only from root file identifier",
 	RunE:  deleteFileFunc,
 }
 
 func deleteFileFunc(cmd *cobra.Command, args []string) error {
-	priv, err := crypto.LoadECDSA(privateKeyFile)
+	signer, err := loadSigner()
 	if err != nil {
 		return err
 	}
@@ -32,10 +31,21 @@ func deleteFileFunc(cmd *cobra.Command, args []string) error {
 	}
 
 	cli := client.New(uri, requestTimeout)
-	if err := tree.Delete(context.Background(), cli, args[0], priv); err != nil {
+	if err := checkNetwork(context.Background(), cli); err != nil {
+		return err
+	}
+	var totalCost uint64
+	if err := tree.Delete(context.Background(), cli, args[0], signer, tree.WithProgress(func(e *tree.Event) {
+		if e.Op != tree.EventSkip {
+			totalCost = e.TotalCost
+		}
+		printEvent(e)
+	}), tree.WithTxOptions(client.WithMaxFee(maxFee))); err != nil {
 		return err
 	}
 
-	color.Green("deleted file %s", args[0])
-	return nil
+	res := &fileResult{Path: args[0], TotalCost: totalCost}
+	return printResult(res, func() {
+		color.Green("deleted file %s", args[0])
+	})
 }
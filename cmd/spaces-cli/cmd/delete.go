@@ -6,8 +6,6 @@ package cmd
 import (
 	"context"
 
-	"github.com/ethereum/go-ethereum/crypto"
-	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 
 	"github.com/ava-labs/spacesvm/chain"
@@ -21,7 +19,7 @@ var deleteCmd = &cobra.Command{
 }
 
 func deleteFunc(cmd *cobra.Command, args []string) error {
-	priv, err := crypto.LoadECDSA(privateKeyFile)
+	signer, err := loadSigner()
 	if err != nil {
 		return err
 	}
@@ -38,15 +36,9 @@ func deleteFunc(cmd *cobra.Command, args []string) error {
 	}
 
 	cli := client.New(uri, requestTimeout)
-	opts := []client.OpOption{client.WithPollTx()}
-	if verbose {
-		opts = append(opts, client.WithInfo(space))
-		opts = append(opts, client.WithBalance())
-	}
-	if _, _, err := client.SignIssueRawTx(context.Background(), cli, utx, priv, opts...); err != nil {
+	res, err := issueTx(context.Background(), cli, utx, signer, space)
+	if err != nil {
 		return err
 	}
-
-	color.Green("deleted %s from %s", key, space)
-	return nil
+	return printTx(res, signer, "deleted %s from %s", key, space)
 }
@@ -0,0 +1,274 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cmd
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/fatih/color"
+	log "github.com/inconshreveable/log15"
+	"github.com/spf13/cobra"
+
+	"github.com/ava-labs/spacesvm/chain"
+	"github.com/ava-labs/spacesvm/devnet"
+)
+
+var (
+	devnetNodes       int
+	devnetHost        string
+	devnetPort        int
+	devnetAccounts    int
+	devnetBalance     uint64
+	devnetFund        []string
+	devnetMagic       uint64
+	devnetGenesisFile string
+	devnetUpgrades    string
+	devnetVMConfig    string
+	devnetProfile     string
+)
+
+func init() {
+	devnetCmd.PersistentFlags().IntVar(
+		&devnetNodes,
+		"nodes",
+		3,
+		"number of VMs to run",
+	)
+	devnetCmd.PersistentFlags().StringVar(
+		&devnetHost,
+		"host",
+		"127.0.0.1",
+		"host the APIs of the VMs listen on",
+	)
+	devnetCmd.PersistentFlags().IntVar(
+		&devnetPort,
+		"port",
+		9650,
+		"port of the first VM (incremented for each VM, 0 picks random ports)",
+	)
+	devnetCmd.PersistentFlags().IntVar(
+		&devnetAccounts,
+		"accounts",
+		2,
+		"number of funded keys to generate",
+	)
+	devnetCmd.PersistentFlags().Uint64Var(
+		&devnetBalance,
+		"balance",
+		10_000_000_000,
+		"balance of each funded address",
+	)
+	devnetCmd.PersistentFlags().StringSliceVar(
+		&devnetFund,
+		"fund",
+		nil,
+		"additional addresses to fund",
+	)
+	devnetCmd.PersistentFlags().Uint64Var(
+		&devnetMagic,
+		"magic",
+		1337,
+		"magic of the generated genesis",
+	)
+	devnetCmd.PersistentFlags().StringVar(
+		&devnetGenesisFile,
+		"genesis",
+		"",
+		"genesis file to use instead of the default genesis (see \"spaces-cli genesis\")",
+	)
+	devnetCmd.PersistentFlags().StringVar(
+		&devnetUpgrades,
+		"upgrades",
+		"",
+		"upgrades file (defaults to enabling all gated txs at launch)",
+	)
+	devnetCmd.PersistentFlags().StringVar(
+		&devnetVMConfig,
+		"vm-config",
+		"",
+		"VM config file",
+	)
+	devnetCmd.PersistentFlags().StringVar(
+		&devnetProfile,
+		"save-profile",
+		"",
+		"name of a profile to save for the first VM (ex: devnet)",
+	)
+}
+
+var devnetCmd = &cobra.Command{
+	Use:   "devnet [options]",
+	Short: "Runs a local network of in-process VMs (without avalanchego)",
+	Long: `
+Runs a local network of in-process VMs until interrupted. All state is kept in
+memory. The genesis funds newly generated keys (and any --fund addresses) and
+every VM serves the same API as a SpacesVM on avalanchego:
+
+$ spaces-cli devnet --nodes 3 --save-profile devnet
+$ spaces-cli key import dev <(echo <private key>)
+$ spaces-cli claim patrick --profile devnet --key dev
+`,
+	RunE: devnetFunc,
+}
+
+type devnetAccount struct {
+	Address    common.Address `json:"address"`
+	PrivateKey string         `json:"privateKey,omitempty"`
+}
+
+type devnetNode struct {
+	NodeID ids.NodeID `json:"nodeId"`
+	URL    string     `json:"url"`
+}
+
+func devnetFunc(cmd *cobra.Command, args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("expected exactly 0 arguments, got %d", len(args))
+	}
+	lvl := log.LvlInfo
+	if verbose {
+		lvl = log.LvlDebug
+	}
+	log.Root().SetHandler(log.LvlFilterHandler(lvl, log.StreamHandler(os.Stderr, log.LogfmtFormat())))
+
+	g, accounts, err := devnetGenesis()
+	if err != nil {
+		return err
+	}
+	genesis, err := json.Marshal(g)
+	if err != nil {
+		return err
+	}
+	cfg := &devnet.Config{
+		Nodes:    devnetNodes,
+		Genesis:  genesis,
+		Host:     devnetHost,
+		BasePort: devnetPort,
+	}
+	if len(devnetUpgrades) > 0 {
+		cfg.Upgrades, err = os.ReadFile(devnetUpgrades)
+	} else {
+		cfg.Upgrades, err = json.Marshal(&chain.Upgrades{Upgrades: []*chain.Upgrade{
+			{Timestamp: 0, EnabledTxs: chain.GatedTxs()},
+		}})
+	}
+	if err != nil {
+		return err
+	}
+	if len(devnetVMConfig) > 0 {
+		cfg.VMConfig, err = os.ReadFile(devnetVMConfig)
+		if err != nil {
+			return err
+		}
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	n, err := devnet.New(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := n.Shutdown(context.Background()); err != nil {
+			log.Error("unable to shutdown devnet", "error", err)
+		}
+	}()
+
+	nodes := make([]*devnetNode, len(n.Nodes))
+	for i, node := range n.Nodes {
+		nodes[i] = &devnetNode{NodeID: node.NodeID, URL: node.URL}
+	}
+	if len(devnetProfile) > 0 {
+		c, err := loadConfig()
+		if err != nil {
+			return err
+		}
+		c.Profiles[devnetProfile] = &profile{Endpoint: nodes[0].URL, ChainID: n.ChainID, Magic: g.Magic}
+		if err := c.save(); err != nil {
+			return err
+		}
+	}
+
+	res := &struct {
+		ChainID  ids.ID           `json:"chainId"`
+		Magic    uint64           `json:"magic"`
+		Nodes    []*devnetNode    `json:"nodes"`
+		Accounts []*devnetAccount `json:"accounts"`
+	}{n.ChainID, g.Magic, nodes, accounts}
+	if err := printResult(res, func() {
+		color.Green("started devnet (chainID=%s magic=%d)", n.ChainID, g.Magic)
+		for _, node := range nodes {
+			color.Cyan("node %s: %s", node.NodeID, node.URL)
+		}
+		for _, a := range accounts {
+			if len(a.PrivateKey) == 0 {
+				color.Cyan("funded address %s", a.Address)
+				continue
+			}
+			color.Cyan("funded address %s (private key %s)", a.Address, a.PrivateKey)
+		}
+		if len(devnetProfile) > 0 {
+			color.Cyan("saved profile %s to %s", devnetProfile, configFile)
+		}
+		color.Yellow("press Ctrl+C to stop")
+	}); err != nil {
+		return err
+	}
+
+	n.Run(ctx)
+	return nil
+}
+
+// devnetGenesis returns the genesis of the devnet and the accounts it funds.
+func devnetGenesis() (*chain.Genesis, []*devnetAccount, error) {
+	g := chain.DefaultGenesis()
+	g.Magic = devnetMagic
+	if len(devnetGenesisFile) > 0 {
+		b, err := os.ReadFile(devnetGenesisFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		g = new(chain.Genesis)
+		if err := json.Unmarshal(b, g); err != nil {
+			return nil, nil, fmt.Errorf("%w: failed to parse genesis", err)
+		}
+		// Airdrop data is not available
+		g.AirdropHash = ""
+		g.AirdropUnits = 0
+	}
+
+	accounts := []*devnetAccount{}
+	for i := 0; i < devnetAccounts; i++ {
+		priv, err := crypto.GenerateKey()
+		if err != nil {
+			return nil, nil, err
+		}
+		accounts = append(accounts, &devnetAccount{
+			Address:    crypto.PubkeyToAddress(priv.PublicKey),
+			PrivateKey: hex.EncodeToString(crypto.FromECDSA(priv)),
+		})
+	}
+	for _, addr := range devnetFund {
+		if !common.IsHexAddress(addr) {
+			return nil, nil, fmt.Errorf("invalid address %q", addr)
+		}
+		accounts = append(accounts, &devnetAccount{Address: common.HexToAddress(addr)})
+	}
+	for _, a := range accounts {
+		g.CustomAllocation = append(g.CustomAllocation, &chain.CustomAllocation{
+			Address: a.Address,
+			Balance: devnetBalance,
+		})
+	}
+	return g, accounts, nil
+}
@@ -0,0 +1,114 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/ava-labs/spacesvm/client"
+	"github.com/ava-labs/spacesvm/parser"
+	"github.com/ava-labs/spacesvm/tree"
+)
+
+var (
+	gcDryRun  bool
+	gcMinAge  time.Duration
+	gcWorkers int
+)
+
+func init() {
+	gcCmd.PersistentFlags().BoolVar(
+		&gcDryRun,
+		"dry-run",
+		false,
+		"only report the orphaned keys and the units and expiry that deleting them would reclaim",
+	)
+	gcCmd.PersistentFlags().DurationVar(
+		&gcMinAge,
+		"min-age",
+		tree.DefaultGCMinAge,
+		"only delete keys that were not updated for this long (protects uploads in progress)",
+	)
+	gcCmd.PersistentFlags().IntVar(
+		&gcWorkers,
+		"workers",
+		tree.DefaultWorkers,
+		"number of keys fetched or deleted concurrently",
+	)
+}
+
+var gcCmd = &cobra.Command{
+	Use:   "gc [options] <space>",
+	Short: "Deletes file chunks that are not reachable from any root",
+	Long: `
+Finds the content-addressed keys of a space that are not reachable from the
+root of any file or directory (like the chunks of a root removed with
+"spaces-cli delete" or of an upload that was never completed) and deletes
+them. Use --dry-run to see how many units and how much expiry would be
+reclaimed first:
+
+$ spaces-cli gc patrick --dry-run
+$ spaces-cli gc patrick
+`,
+	RunE: gcFunc,
+}
+
+func gcFunc(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("expected exactly 1 argument, got %d", len(args))
+	}
+	space := args[0]
+	if err := parser.CheckContents(space); err != nil {
+		return fmt.Errorf("%w: failed to parse space", err)
+	}
+
+	opts := []tree.OpOption{
+		tree.WithProgress(printEvent),
+		tree.WithMinAge(gcMinAge),
+		tree.WithWorkers(gcWorkers),
+		tree.WithTxOptions(client.WithMaxFee(maxFee)),
+	}
+	var signer client.Signer
+	if gcDryRun {
+		opts = append(opts, tree.WithDryRun())
+	} else {
+		var err error
+		signer, err = loadSigner()
+		if err != nil {
+			return err
+		}
+	}
+
+	cli := client.New(uri, requestTimeout)
+	if err := checkNetwork(context.Background(), cli); err != nil {
+		return err
+	}
+	r, err := tree.GC(context.Background(), cli, signer, space, opts...)
+	if err != nil {
+		return err
+	}
+
+	return printResult(r, func() {
+		color.Cyan(
+			"found %d orphaned keys (%d bytes) among %d content-addressed keys and %d roots",
+			len(r.Orphans), r.Size, r.Keys, r.Roots,
+		)
+		expiry := time.Unix(int64(r.NewExpiry), 0)
+		color.Cyan(
+			"units=%d -> %d (%d reclaimed), expiry=%v (+%v)",
+			r.Units, r.NewUnits, r.Units-r.NewUnits, expiry, time.Duration(r.NewExpiry-r.Expiry)*time.Second,
+		)
+		switch {
+		case gcDryRun && len(r.Orphans) > 0:
+			color.Yellow("dry run: run again without --dry-run to delete them")
+		case !gcDryRun:
+			color.Green("deleted %d orphaned keys from %s totalCost=%d", len(r.Orphans), space, r.TotalCost)
+		}
+	})
+}
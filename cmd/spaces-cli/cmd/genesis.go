@@ -116,6 +116,10 @@ func genesisFunc(cmd *cobra.Command, args []string) error {
 	if err := os.WriteFile(genesisFile, b, fsModeWrite); err != nil {
 		return err
 	}
-	color.Green("created genesis and saved to %s", genesisFile)
-	return nil
+	res := &struct {
+		Path string `json:"path"`
+	}{genesisFile}
+	return printResult(res, func() {
+		color.Green("created genesis and saved to %s", genesisFile)
+	})
 }
@@ -11,6 +11,7 @@ import (
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 
+	"github.com/ava-labs/spacesvm/chain"
 	"github.com/ava-labs/spacesvm/client"
 )
 
@@ -30,13 +31,15 @@ func infoFunc(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	client.PPInfo(info)
-	for _, kv := range values {
-		hr, err := json.Marshal(kv.ValueMeta)
-		if err != nil {
-			return err
+	res := &struct {
+		Info   *chain.SpaceInfo      `json:"info"`
+		Values []*chain.KeyValueMeta `json:"values"`
+	}{info, values}
+	return printResult(res, func() {
+		printInfo(info)
+		for _, kv := range values {
+			hr, _ := json.Marshal(kv.ValueMeta)
+			color.Yellow("%s=>%s", kv.Key, string(hr))
 		}
-		color.Yellow("%s=>%s", kv.Key, string(hr))
-	}
-	return nil
+	})
 }
@@ -0,0 +1,300 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cmd
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/ava-labs/spacesvm/client"
+	"github.com/ava-labs/spacesvm/keystore"
+)
+
+// passphraseEnv is read instead of prompting for a passphrase (for
+// non-interactive use).
+const passphraseEnv = "SPACES_CLI_PASSPHRASE"
+
+// signerTokenEnv is sent as the bearer token of --remote-signer requests.
+const signerTokenEnv = "SPACES_CLI_SIGNER_TOKEN"
+
+var exportPlaintext bool
+
+func init() {
+	keyCmd.AddCommand(
+		keyListCmd,
+		keyImportCmd,
+		keyExportCmd,
+		keyPublicCmd,
+	)
+	keyExportCmd.PersistentFlags().BoolVar(
+		&exportPlaintext,
+		"plaintext",
+		false,
+		"export the unencrypted private key (hex)",
+	)
+}
+
+var keyCmd = &cobra.Command{
+	Use:   "key",
+	Short: "Manages keys in the encrypted keystore",
+	Long: fmt.Sprintf(`
+Manages named keys in the encrypted keystore (--keystore). Keys are stored
+in the keystore v3 JSON format used by geth.
+
+The passphrase is prompted for unless %s is set.
+`, passphraseEnv),
+}
+
+var keyListCmd = &cobra.Command{
+	Use:   "list [options]",
+	Short: "Lists all keys in the keystore",
+	RunE:  keyListFunc,
+}
+
+func keyListFunc(cmd *cobra.Command, args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("expected exactly 0 arguments, got %d", len(args))
+	}
+	entries, err := keystore.New(keystoreDir).List()
+	if err != nil {
+		return err
+	}
+	return printResult(entries, func() {
+		if len(entries) == 0 {
+			color.Cyan("no keys in %s", keystoreDir)
+		}
+		for _, e := range entries {
+			color.Cyan("%s %s", e.Name, e.Address)
+		}
+	})
+}
+
+var keyImportCmd = &cobra.Command{
+	Use:   "import [options] <name> <file>",
+	Short: "Imports a plaintext (hex) or keystore v3 JSON key file",
+	RunE:  keyImportFunc,
+}
+
+func keyImportFunc(cmd *cobra.Command, args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("expected exactly 2 arguments, got %d", len(args))
+	}
+	name, file := args[0], args[1]
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	ks := keystore.New(keystoreDir)
+	var priv *ecdsa.PrivateKey
+	if bytes.HasPrefix(bytes.TrimSpace(b), []byte("{")) {
+		// The passphrase of the imported key is kept
+		passphrase, err := readPassphrase(name, false)
+		if err != nil {
+			return err
+		}
+		priv, err = ks.ImportJSON(name, b, passphrase)
+		if err != nil {
+			return err
+		}
+	} else {
+		priv, err = crypto.LoadECDSA(file)
+		if err != nil {
+			return err
+		}
+		passphrase, err := readPassphrase(name, true)
+		if err != nil {
+			return err
+		}
+		if err := ks.Import(name, priv, passphrase); err != nil {
+			return err
+		}
+	}
+	res := newKeyResult(name, priv, ks.Path(name))
+	return printResult(res, func() {
+		color.Green("imported address %s as %s", res.Address, name)
+	})
+}
+
+var keyExportCmd = &cobra.Command{
+	Use:   "export [options] <name>",
+	Short: "Writes a key (keystore v3 JSON by default) to stdout",
+	RunE:  keyExportFunc,
+}
+
+func keyExportFunc(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("expected exactly 1 argument, got %d", len(args))
+	}
+	ks := keystore.New(keystoreDir)
+	if !exportPlaintext {
+		b, err := ks.Export(args[0])
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+		return nil
+	}
+	passphrase, err := readPassphrase(args[0], false)
+	if err != nil {
+		return err
+	}
+	priv, err := ks.Load(args[0], passphrase)
+	if err != nil {
+		return err
+	}
+	pk := hex.EncodeToString(crypto.FromECDSA(priv))
+	res := &struct {
+		PrivateKey string `json:"privateKey"`
+	}{pk}
+	return printResult(res, func() {
+		fmt.Println(pk)
+	})
+}
+
+var keyPublicCmd = &cobra.Command{
+	Use:   "public [options]",
+	Short: "Prints the public key of --key (or --private-key-file)",
+	Long: `
+Prints the (compressed) public key of --key, or of --private-key-file if it
+is set. Share it with others so they can encrypt values and files to you
+with --encrypt-to.
+`,
+	RunE: keyPublicFunc,
+}
+
+func keyPublicFunc(cmd *cobra.Command, args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("expected exactly 0 arguments, got %d", len(args))
+	}
+	priv, err := loadKey()
+	if err != nil {
+		return err
+	}
+	res := newKeyResult(keyName, priv, "")
+	return printResult(res, func() {
+		fmt.Println(res.PublicKey)
+	})
+}
+
+// loadSigner returns the signer selected by --remote-signer,
+// --private-key-file, or --key (in that order).
+func loadSigner() (client.Signer, error) {
+	if len(remoteSigner) > 0 {
+		if !common.IsHexAddress(signerAddress) {
+			return nil, errors.New("--signer-address must be set with --remote-signer")
+		}
+		return client.NewRemoteSigner(
+			remoteSigner, common.HexToAddress(signerAddress), os.Getenv(signerTokenEnv), requestTimeout,
+		), nil
+	}
+	priv, err := loadKey()
+	if err != nil {
+		return nil, err
+	}
+	return client.NewKeySigner(priv), nil
+}
+
+// loadKey returns the private key selected by --private-key-file or --key (in
+// that order). If neither exists, the plaintext key that older versions read
+// from [legacyPrivateKeyFile] by default is used (if present). Remote signers
+// do not expose their key, so they cannot be used to decrypt.
+func loadKey() (*ecdsa.PrivateKey, error) {
+	if len(remoteSigner) > 0 {
+		return nil, errors.New("cannot decrypt with --remote-signer (use --key or --private-key-file)")
+	}
+	if len(privateKeyFile) > 0 {
+		color.Yellow("using plaintext key %s (import it with \"spaces-cli key import\")", privateKeyFile)
+		return crypto.LoadECDSA(privateKeyFile)
+	}
+	ks := keystore.New(keystoreDir)
+	if _, err := os.Stat(ks.Path(keyName)); errors.Is(err, os.ErrNotExist) {
+		if _, err := os.Stat(legacyPrivateKeyFile); err == nil {
+			color.Yellow(
+				"DEPRECATED: using plaintext key %s because key %q does not exist (import it with \"spaces-cli key import %s %s\")",
+				legacyPrivateKeyFile, keyName, keyName, legacyPrivateKeyFile,
+			)
+			return crypto.LoadECDSA(legacyPrivateKeyFile)
+		}
+	}
+	passphrase, err := readPassphrase(keyName, false)
+	if err != nil {
+		return nil, err
+	}
+	priv, err := ks.Load(keyName, passphrase)
+	if errors.Is(err, keystore.ErrKeyMissing) {
+		return nil, fmt.Errorf("%w (create one with \"spaces-cli create\")", err)
+	}
+	return priv, err
+}
+
+// keyLoader returns a function that calls [loadKey] the first time it is
+// called (so the passphrase is only prompted for once encrypted data is
+// found).
+func keyLoader() func() (*ecdsa.PrivateKey, error) {
+	var (
+		priv *ecdsa.PrivateKey
+		err  error
+	)
+	return func() (*ecdsa.PrivateKey, error) {
+		if priv == nil && err == nil {
+			priv, err = loadKey()
+		}
+		return priv, err
+	}
+}
+
+// loadRecipients parses the public keys passed to --encrypt-to.
+func loadRecipients() ([]*ecdsa.PublicKey, error) {
+	recipients := make([]*ecdsa.PublicKey, len(encryptTo))
+	for i, k := range encryptTo {
+		pub, err := client.ParsePublicKey(k)
+		if err != nil {
+			return nil, fmt.Errorf("%w: --encrypt-to %s", err, k)
+		}
+		recipients[i] = pub
+	}
+	return recipients, nil
+}
+
+// readPassphrase reads the passphrase of key [name] from [passphraseEnv] or
+// prompts for it (twice if [confirm]).
+func readPassphrase(name string, confirm bool) (string, error) {
+	if passphrase, ok := os.LookupEnv(passphraseEnv); ok {
+		return passphrase, nil
+	}
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", fmt.Errorf("no terminal to read passphrase from (set %s)", passphraseEnv)
+	}
+	fmt.Fprintf(os.Stderr, "Passphrase for key %q: ", name)
+	b, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	if !confirm {
+		return string(b), nil
+	}
+	fmt.Fprint(os.Stderr, "Repeat passphrase: ")
+	b2, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	if !bytes.Equal(b, b2) {
+		return "", errors.New("passphrases do not match")
+	}
+	return string(b), nil
+}
@@ -9,8 +9,6 @@ import (
 	"strconv"
 	"strings"
 
-	"github.com/ethereum/go-ethereum/crypto"
-	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 
 	"github.com/ava-labs/spacesvm/chain"
@@ -25,7 +23,7 @@ var lifelineCmd = &cobra.Command{
 }
 
 func lifelineFunc(cmd *cobra.Command, args []string) error {
-	priv, err := crypto.LoadECDSA(privateKeyFile)
+	signer, err := loadSigner()
 	if err != nil {
 		return err
 	}
@@ -42,17 +40,11 @@ func lifelineFunc(cmd *cobra.Command, args []string) error {
 	}
 
 	cli := client.New(uri, requestTimeout)
-	opts := []client.OpOption{client.WithPollTx()}
-	if verbose {
-		opts = append(opts, client.WithInfo(space))
-		opts = append(opts, client.WithBalance())
-	}
-	if _, _, err := client.SignIssueRawTx(context.Background(), cli, utx, priv, opts...); err != nil {
+	res, err := issueTx(context.Background(), cli, utx, signer, space)
+	if err != nil {
 		return err
 	}
-
-	color.Green("extended life of %s by %d units", space, units)
-	return nil
+	return printTx(res, signer, "extended life of %s by %d units", space, units)
 }
 
 func getLifelineOp(args []string) (space string, units uint64, err error) {
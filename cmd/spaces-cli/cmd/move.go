@@ -8,8 +8,6 @@ import (
 	"fmt"
 
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/crypto"
-	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 
 	"github.com/ava-labs/spacesvm/chain"
@@ -24,7 +22,7 @@ var moveCmd = &cobra.Command{
 }
 
 func moveFunc(cmd *cobra.Command, args []string) error {
-	priv, err := crypto.LoadECDSA(privateKeyFile)
+	signer, err := loadSigner()
 	if err != nil {
 		return err
 	}
@@ -41,17 +39,11 @@ func moveFunc(cmd *cobra.Command, args []string) error {
 	}
 
 	cli := client.New(uri, requestTimeout)
-	opts := []client.OpOption{client.WithPollTx()}
-	if verbose {
-		opts = append(opts, client.WithInfo(space))
-		opts = append(opts, client.WithBalance())
-	}
-	if _, _, err := client.SignIssueRawTx(context.Background(), cli, utx, priv, opts...); err != nil {
+	res, err := issueTx(context.Background(), cli, utx, signer, space)
+	if err != nil {
 		return err
 	}
-
-	color.Green("moved %s to %s", space, to.Hex())
-	return nil
+	return printTx(res, signer, "moved %s to %s", space, to.Hex())
 }
 
 func getMoveOp(args []string) (to common.Address, space string, err error) {
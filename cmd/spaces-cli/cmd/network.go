@@ -7,6 +7,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/ava-labs/avalanchego/ids"
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 
@@ -28,6 +29,12 @@ func networkFunc(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	color.Cyan("networkID=%d subnetID=%s chainID=%s", networkID, subnetID, chainID)
-	return nil
+	res := &struct {
+		NetworkID uint32 `json:"networkId"`
+		SubnetID  ids.ID `json:"subnetId"`
+		ChainID   ids.ID `json:"chainId"`
+	}{networkID, subnetID, chainID}
+	return printResult(res, func() {
+		color.Cyan("networkID=%d subnetID=%s chainID=%s", networkID, subnetID, chainID)
+	})
 }
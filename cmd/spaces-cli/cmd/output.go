@@ -0,0 +1,266 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cmd
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	ethkeystore "github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/ava-labs/spacesvm/chain"
+	"github.com/ava-labs/spacesvm/client"
+	"github.com/ava-labs/spacesvm/keystore"
+	"github.com/ava-labs/spacesvm/tree"
+)
+
+const (
+	outputText = "text"
+	outputJSON = "json"
+)
+
+// errorCodes are reported with errors in [outputJSON] mode. Errors returned
+// by the VM only contain the message, so they are also matched by text.
+var errorCodes = []struct {
+	code string
+	err  error
+}{
+	{"space_missing", chain.ErrSpaceMissing},
+	{"space_expired", chain.ErrSpaceExpired},
+	{"space_not_expired", chain.ErrSpaceNotExpired},
+	{"key_missing", chain.ErrKeyMissing},
+	{"unauthorized", chain.ErrUnauthorized},
+	{"insufficient_balance", chain.ErrInvalidBalance},
+	{"insufficient_price", chain.ErrInsufficientPrice},
+	{"invalid_block_id", chain.ErrInvalidBlockID},
+	{"invalid_signature", chain.ErrInvalidSignature},
+	{"duplicate_tx", chain.ErrDuplicateTx},
+	{"value_too_big", chain.ErrValueTooBig},
+	{"non_actionable", chain.ErrNonActionable},
+	{"tx_not_enabled", chain.ErrTxNotEnabled},
+	{"not_confirmed", client.ErrNotConfirmed},
+	{"fee_too_high", client.ErrFeeTooHigh},
+	{"remote_signer", client.ErrRemoteSigner},
+	{"signer_mismatch", client.ErrSignerMismatch},
+	{"integrity_failure", client.ErrIntegrityFailure},
+	{"not_recipient", client.ErrNotRecipient},
+	{"invalid_sealed", client.ErrInvalidSealed},
+	{"invalid_public_key", client.ErrInvalidPublicKey},
+	{"file_missing", tree.ErrMissing},
+	{"file_empty", tree.ErrEmpty},
+	{"manifest_mismatch", tree.ErrManifestMismatch},
+	{"integrity_failure", tree.ErrIntegrity},
+	{"not_dir", tree.ErrNotDir},
+	{"dir_too_large", tree.ErrDirTooLarge},
+	{"file_shared", tree.ErrShared},
+	{"root_too_large", tree.ErrRootTooLarge},
+	{"invalid_name", tree.ErrInvalidName},
+	{"file_encrypted", tree.ErrEncrypted},
+	{"unsupported_compression", tree.ErrUnsupportedCompression},
+	{"keystore_key_missing", keystore.ErrKeyMissing},
+	{"keystore_key_exists", keystore.ErrKeyExists},
+	{"invalid_passphrase", ethkeystore.ErrDecrypt},
+	{"profile_missing", errProfileMissing},
+	{"wrong_network", errWrongNetwork},
+}
+
+// errorCode returns the code of the first entry in [errorCodes] that
+// matches [err] ("unknown" if there is none).
+func errorCode(err error) string {
+	for _, e := range errorCodes {
+		if errors.Is(err, e.err) || strings.Contains(err.Error(), e.err.Error()) {
+			return e.code
+		}
+	}
+	return "unknown"
+}
+
+// PrintError reports an error returned by [Execute].
+func PrintError(err error) {
+	if outputFormat != outputJSON {
+		color.Red("spaces-cli failed: %v", err)
+		return
+	}
+	_ = json.NewEncoder(os.Stdout).Encode(map[string]interface{}{
+		"error": map[string]string{
+			"code":    errorCode(err),
+			"message": err.Error(),
+		},
+	})
+}
+
+// checkOutput validates --output. In [outputJSON] mode, human-readable
+// progress is moved to stderr so that stdout only contains the result.
+func checkOutput(cmd *cobra.Command) error {
+	switch outputFormat {
+	case outputText:
+	case outputJSON:
+		color.Output = os.Stderr
+		cmd.Root().SilenceErrors = true
+		cmd.Root().SilenceUsage = true
+	default:
+		return fmt.Errorf("unknown output format %q", outputFormat)
+	}
+	return nil
+}
+
+// printResult writes [v] to stdout in [outputJSON] mode and calls [text]
+// otherwise.
+func printResult(v interface{}, text func()) error {
+	if outputFormat == outputJSON {
+		return json.NewEncoder(os.Stdout).Encode(v)
+	}
+	text()
+	return nil
+}
+
+type txResult struct {
+	TxID ids.ID `json:"txId"`
+	Cost uint64 `json:"cost"`
+
+	// Populated with --verbose
+	Info    *chain.SpaceInfo `json:"info,omitempty"`
+	Balance *uint64          `json:"balance,omitempty"`
+}
+
+// issueTx signs and issues [utx] and waits for it to be accepted. The info
+// of [space] (if not empty) and the balance of the signer are fetched
+// afterwards with --verbose.
+func issueTx(
+	ctx context.Context,
+	cli client.Client,
+	utx chain.UnsignedTransaction,
+	signer client.Signer,
+	space string,
+) (*txResult, error) {
+	if err := checkNetwork(ctx, cli); err != nil {
+		return nil, err
+	}
+	color.Yellow("issuing %s tx", utx.Activity().Typ)
+	txID, cost, err := client.SignIssueRawTx(ctx, cli, utx, signer, txOptions()...)
+	if err != nil {
+		return nil, err
+	}
+	res := &txResult{TxID: txID, Cost: cost}
+	if !verbose {
+		return res, nil
+	}
+	if len(space) > 0 {
+		info, _, err := cli.Info(ctx, space)
+		if err != nil {
+			return nil, err
+		}
+		res.Info = info
+	}
+	bal, err := cli.Balance(ctx, signer.Address())
+	if err != nil {
+		return nil, err
+	}
+	res.Balance = &bal
+	return res, nil
+}
+
+// printTx prints the result of [issueTx] and a summary described by [format].
+func printTx(res *txResult, signer client.Signer, format string, args ...interface{}) error {
+	return printResult(res, func() {
+		color.Yellow("transaction %s confirmed (cost=%d)", res.TxID, res.Cost)
+		if res.Info != nil {
+			printInfo(res.Info)
+		}
+		if res.Balance != nil {
+			color.Cyan("address=%s balance=%d", signer.Address(), *res.Balance)
+		}
+		color.Green(format, args...)
+	})
+}
+
+// keyResult is reported by commands that add a key to the keystore.
+type keyResult struct {
+	Name      string         `json:"name"`
+	Address   common.Address `json:"address"`
+	PublicKey string         `json:"publicKey"`
+	Path      string         `json:"path,omitempty"`
+}
+
+func newKeyResult(name string, priv *ecdsa.PrivateKey, path string) *keyResult {
+	return &keyResult{
+		Name:      name,
+		Address:   crypto.PubkeyToAddress(priv.PublicKey),
+		PublicKey: hexutil.Encode(crypto.CompressPubkey(&priv.PublicKey)),
+		Path:      path,
+	}
+}
+
+// fileResult is reported by the file commands.
+type fileResult struct {
+	Path      string `json:"path"`
+	File      string `json:"file,omitempty"`
+	Dir       string `json:"dir,omitempty"`
+	Size      uint64 `json:"size,omitempty"`
+	TotalCost uint64 `json:"totalCost,omitempty"`
+	// Cost saved by --compress
+	Saved uint64 `json:"saved,omitempty"`
+
+	// Populated from the [tree.FileMeta] of resolved files
+	Name        string `json:"name,omitempty"`
+	ContentType string `json:"contentType,omitempty"`
+	SHA256      string `json:"sha256,omitempty"`
+}
+
+// printSaved reports the cost saved by --compress (if any).
+func printSaved(totalCost uint64, saved uint64) {
+	if saved == 0 {
+		return
+	}
+	color.Cyan(
+		"compression saved %d (%.1f%% of %d)",
+		saved, 100*float64(saved)/float64(totalCost+saved), totalCost+saved,
+	)
+}
+
+func printInfo(info *chain.SpaceInfo) {
+	expiry := time.Unix(int64(info.Expiry), 0)
+	color.Cyan(
+		"raw space %s: units=%d expiry=%v (%v remaining)",
+		info.RawSpace, info.Units, expiry, time.Until(expiry),
+	)
+}
+
+// printEvent prints the progress of a file operation (in text mode).
+func printEvent(e *tree.Event) {
+	name := "chunk"
+	switch {
+	case e.Root:
+		name = "root"
+	case e.Index:
+		name = "index"
+	}
+	switch e.Op {
+	case tree.EventSkip:
+		color.Yellow("already processed %s=%s, skipping", name, e.Key)
+	case tree.EventDownload:
+		color.Yellow("downloaded %s=%s size=%d", name, e.Key, e.Size)
+	case tree.EventReissue:
+		color.Yellow("%s=%s not confirmed (txID=%s), reissuing", name, e.Key, e.TxID)
+	case tree.EventUpload:
+		color.Yellow(
+			"uploaded %s=%s txID=%s cost=%d totalCost=%d saved=%d",
+			name, e.Key, e.TxID, e.Cost, e.TotalCost, e.Saved,
+		)
+	case tree.EventDelete:
+		color.Yellow("deleted %s=%s txID=%s cost=%d totalCost=%d", name, e.Key, e.TxID, e.Cost, e.TotalCost)
+	}
+}
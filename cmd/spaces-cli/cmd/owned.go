@@ -6,7 +6,7 @@ package cmd
 import (
 	"context"
 
-	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 
@@ -15,16 +15,16 @@ import (
 
 var ownedCmd = &cobra.Command{
 	Use:   "owned [options]",
-	Short: "Fetches all owned spaces for the address associated with the private key",
+	Short: "Fetches all owned spaces for the address of the signer",
 	RunE:  ownedFunc,
 }
 
 func ownedFunc(cmd *cobra.Command, args []string) error {
-	priv, err := crypto.LoadECDSA(privateKeyFile)
+	signer, err := loadSigner()
 	if err != nil {
 		return err
 	}
-	sender := crypto.PubkeyToAddress(priv.PublicKey)
+	sender := signer.Address()
 
 	cli := client.New(uri, requestTimeout)
 	spaces, err := cli.Owned(context.Background(), sender)
@@ -32,6 +32,11 @@ func ownedFunc(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	color.Green("address %s owns %+v", sender.Hex(), spaces)
-	return nil
+	res := &struct {
+		Address common.Address `json:"address"`
+		Spaces  []string       `json:"spaces"`
+	}{sender, spaces}
+	return printResult(res, func() {
+		color.Green("address %s owns %+v", sender.Hex(), spaces)
+	})
 }
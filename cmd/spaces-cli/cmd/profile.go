@@ -0,0 +1,347 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+
+	"github.com/ava-labs/spacesvm/client"
+)
+
+var (
+	errProfileMissing = errors.New("profile not found")
+	errWrongNetwork   = errors.New("endpoint does not match profile")
+)
+
+var (
+	configFile  string
+	profileName string
+
+	// Set by the selected profile (if any)
+	activeProfile *profile
+	maxFee        uint64
+
+	profileMaxFee  uint64
+	profileTimeout time.Duration
+)
+
+// config is stored (as YAML) at --config.
+type config struct {
+	Current  string              `json:"current,omitempty"`
+	Profiles map[string]*profile `json:"profiles,omitempty"`
+}
+
+// profile bundles the settings used to interact with a single network. Flags
+// that are set explicitly take precedence over the profile.
+type profile struct {
+	Endpoint      string `json:"endpoint"`
+	Key           string `json:"key,omitempty"`
+	RemoteSigner  string `json:"remoteSigner,omitempty"`
+	SignerAddress string `json:"signerAddress,omitempty"`
+	MaxFee        uint64 `json:"maxFee,omitempty"`
+	Timeout       string `json:"timeout,omitempty"`
+
+	// Pinned when the profile is added and checked before signing
+	ChainID ids.ID `json:"chainId"`
+	Magic   uint64 `json:"magic"`
+}
+
+func init() {
+	profileCmd.AddCommand(
+		profileListCmd,
+		profileAddCmd,
+		profileUseCmd,
+		profileRemoveCmd,
+	)
+
+	profileAddCmd.PersistentFlags().Uint64Var(
+		&profileMaxFee,
+		"max-fee",
+		0,
+		"maximum cost of a single transaction (0 is unlimited)",
+	)
+	profileAddCmd.PersistentFlags().DurationVar(
+		&profileTimeout,
+		"timeout",
+		0,
+		"request timeout (defaults to 30s)",
+	)
+}
+
+func loadConfig() (*config, error) {
+	c := &config{Profiles: map[string]*profile{}}
+	b, err := os.ReadFile(configFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(b, c); err != nil {
+		return nil, fmt.Errorf("%w: failed to parse %s", err, configFile)
+	}
+	if c.Profiles == nil {
+		c.Profiles = map[string]*profile{}
+	}
+	return c, nil
+}
+
+func (c *config) save() error {
+	b, err := yaml.Marshal(c)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(configFile), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(configFile, b, fsModeWrite)
+}
+
+// applyProfile loads the profile selected by --profile (or the current
+// profile of the config) and uses it for every flag that was not set.
+func applyProfile(cmd *cobra.Command) error {
+	c, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	name := profileName
+	if len(name) == 0 {
+		name = c.Current
+	}
+	if len(name) == 0 {
+		return nil
+	}
+	p, ok := c.Profiles[name]
+	if !ok {
+		return fmt.Errorf("%w: %s", errProfileMissing, name)
+	}
+
+	flags := cmd.Flags()
+	for _, f := range []struct {
+		name  string
+		value string
+		dst   *string
+	}{
+		{"endpoint", p.Endpoint, &uri},
+		{"key", p.Key, &keyName},
+		{"remote-signer", p.RemoteSigner, &remoteSigner},
+		{"signer-address", p.SignerAddress, &signerAddress},
+	} {
+		if len(f.value) > 0 && !flags.Changed(f.name) {
+			*f.dst = f.value
+		}
+	}
+	if len(p.Timeout) > 0 {
+		requestTimeout, err = time.ParseDuration(p.Timeout)
+		if err != nil {
+			return fmt.Errorf("%w: invalid timeout in profile %s", err, name)
+		}
+	}
+	maxFee = p.MaxFee
+	activeProfile = p
+	return nil
+}
+
+// checkNetwork ensures [cli] is connected to the network pinned by the
+// active profile.
+func checkNetwork(ctx context.Context, cli client.Client) error {
+	if activeProfile == nil {
+		return nil
+	}
+	_, _, chainID, err := cli.Network(ctx)
+	if err != nil {
+		return err
+	}
+	if chainID != activeProfile.ChainID {
+		return fmt.Errorf("%w: expected chainID %s, found %s at %s", errWrongNetwork, activeProfile.ChainID, chainID, uri)
+	}
+	g, err := cli.Genesis(ctx)
+	if err != nil {
+		return err
+	}
+	return checkMagic(g.Magic)
+}
+
+// checkMagic ensures a transaction with [magic] can only be replayed on the
+// network pinned by the active profile.
+func checkMagic(magic uint64) error {
+	if activeProfile == nil || magic == activeProfile.Magic {
+		return nil
+	}
+	return fmt.Errorf("%w: expected magic %d, found %d", errWrongNetwork, activeProfile.Magic, magic)
+}
+
+// txOptions returns the options used for every issued transaction.
+func txOptions() []client.OpOption {
+	return []client.OpOption{client.WithPollTx(), client.WithMaxFee(maxFee)}
+}
+
+var profileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "Manages named network profiles",
+	Long: `
+Profiles bundle the endpoint, key, fee limit, and timeout of a network and
+are stored in the config file (--config). The chainID and magic of the
+network are recorded when a profile is added and verified before signing.
+
+$ spaces-cli profile add local http://localhost:9650/ext/bc/... --key dev
+$ spaces-cli profile add prod https://api.tryspaces.xyz --max-fee 100000
+$ spaces-cli profile use local
+$ spaces-cli claim patrick --profile prod
+`,
+}
+
+var profileListCmd = &cobra.Command{
+	Use:   "list [options]",
+	Short: "Lists all profiles (* marks the current profile)",
+	RunE:  profileListFunc,
+}
+
+func profileListFunc(cmd *cobra.Command, args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("expected exactly 0 arguments, got %d", len(args))
+	}
+	c, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	return printResult(c, func() {
+		if len(c.Profiles) == 0 {
+			color.Cyan("no profiles in %s", configFile)
+		}
+		names := make([]string, 0, len(c.Profiles))
+		for name := range c.Profiles {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			p := c.Profiles[name]
+			current := " "
+			if name == c.Current {
+				current = "*"
+			}
+			color.Cyan(
+				"%s %s endpoint=%s key=%s chainID=%s magic=%d maxFee=%d",
+				current, name, p.Endpoint, p.Key, p.ChainID, p.Magic, p.MaxFee,
+			)
+		}
+	})
+}
+
+var profileAddCmd = &cobra.Command{
+	Use:   "add [options] <name> <endpoint>",
+	Short: "Adds (or replaces) a profile and pins the network of the endpoint",
+	RunE:  profileAddFunc,
+}
+
+func profileAddFunc(cmd *cobra.Command, args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("expected exactly 2 arguments, got %d", len(args))
+	}
+	c, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	p := &profile{Endpoint: args[1], MaxFee: profileMaxFee}
+	if profileTimeout > 0 {
+		p.Timeout = profileTimeout.String()
+	}
+	flags := cmd.Flags()
+	if flags.Changed("key") {
+		p.Key = keyName
+	}
+	if flags.Changed("remote-signer") {
+		p.RemoteSigner = remoteSigner
+		p.SignerAddress = signerAddress
+	}
+
+	ctx := context.Background()
+	cli := client.New(p.Endpoint, requestTimeout)
+	_, _, p.ChainID, err = cli.Network(ctx)
+	if err != nil {
+		return err
+	}
+	g, err := cli.Genesis(ctx)
+	if err != nil {
+		return err
+	}
+	p.Magic = g.Magic
+
+	c.Profiles[args[0]] = p
+	if len(c.Current) == 0 {
+		c.Current = args[0]
+	}
+	if err := c.save(); err != nil {
+		return err
+	}
+	return printResult(p, func() {
+		color.Green("added profile %s (chainID=%s magic=%d) to %s", args[0], p.ChainID, p.Magic, configFile)
+	})
+}
+
+var profileUseCmd = &cobra.Command{
+	Use:   "use [options] <name>",
+	Short: "Sets the current profile",
+	RunE:  profileUseFunc,
+}
+
+func profileUseFunc(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("expected exactly 1 argument, got %d", len(args))
+	}
+	c, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if _, ok := c.Profiles[args[0]]; !ok {
+		return fmt.Errorf("%w: %s", errProfileMissing, args[0])
+	}
+	c.Current = args[0]
+	if err := c.save(); err != nil {
+		return err
+	}
+	return printResult(c, func() {
+		color.Green("using profile %s", args[0])
+	})
+}
+
+var profileRemoveCmd = &cobra.Command{
+	Use:   "remove [options] <name>",
+	Short: "Removes a profile",
+	RunE:  profileRemoveFunc,
+}
+
+func profileRemoveFunc(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("expected exactly 1 argument, got %d", len(args))
+	}
+	c, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if _, ok := c.Profiles[args[0]]; !ok {
+		return fmt.Errorf("%w: %s", errProfileMissing, args[0])
+	}
+	delete(c.Profiles, args[0])
+	if c.Current == args[0] {
+		c.Current = ""
+	}
+	if err := c.save(); err != nil {
+		return err
+	}
+	return printResult(c, func() {
+		color.Green("removed profile %s", args[0])
+	})
+}
@@ -0,0 +1,69 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/ava-labs/spacesvm/client"
+	"github.com/ava-labs/spacesvm/tree"
+)
+
+func init() {
+	resolveDirCmd.PersistentFlags().IntVar(
+		&downloadWorkers,
+		"workers",
+		tree.DefaultWorkers,
+		"number of chunks downloaded concurrently",
+	)
+	resolveDirCmd.PersistentFlags().IntVar(
+		&downloadRetries,
+		"retries",
+		tree.DefaultRetries,
+		"number of times a chunk download is retried",
+	)
+	resolveDirCmd.PersistentFlags().StringSliceVar(
+		&downloadEndpoints,
+		"endpoints",
+		nil,
+		"additional RPC endpoints to download chunks from",
+	)
+}
+
+var resolveDirCmd = &cobra.Command{
+	Use:   "resolve-dir [options] <space/key> <output path>",
+	Short: "Reads a directory at space/key and saves it to disk",
+	RunE:  resolveDirFunc,
+}
+
+func resolveDirFunc(cmd *cobra.Command, args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("expected exactly 2 arguments, got %d", len(args))
+	}
+
+	cli := client.New(uri, requestTimeout)
+	clis := make([]client.Client, len(downloadEndpoints))
+	for i, endpoint := range downloadEndpoints {
+		clis[i] = client.New(endpoint, requestTimeout)
+	}
+	var size uint64
+	if err := tree.DownloadDir(context.Background(), cli, args[0], args[1], tree.WithProgress(func(e *tree.Event) {
+		if !e.Index {
+			size += uint64(e.Size)
+		}
+		printEvent(e)
+	}), tree.WithWorkers(downloadWorkers), tree.WithRetries(downloadRetries), tree.WithClients(clis...),
+		tree.WithDecryptionKey(keyLoader())); err != nil {
+		return err
+	}
+
+	res := &fileResult{Path: args[0], Dir: args[1], Size: size}
+	return printResult(res, func() {
+		color.Green("resolved directory %s and stored at %s", args[0], args[1])
+	})
+}
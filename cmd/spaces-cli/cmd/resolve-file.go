@@ -16,6 +16,33 @@ import (
 	"github.com/ava-labs/spacesvm/tree"
 )
 
+var (
+	downloadWorkers   int
+	downloadRetries   int
+	downloadEndpoints []string
+)
+
+func init() {
+	resolveFileCmd.PersistentFlags().IntVar(
+		&downloadWorkers,
+		"workers",
+		tree.DefaultWorkers,
+		"number of chunks downloaded concurrently",
+	)
+	resolveFileCmd.PersistentFlags().IntVar(
+		&downloadRetries,
+		"retries",
+		tree.DefaultRetries,
+		"number of times a chunk download is retried",
+	)
+	resolveFileCmd.PersistentFlags().StringSliceVar(
+		&downloadEndpoints,
+		"endpoints",
+		nil,
+		"additional RPC endpoints to download chunks from",
+	)
+}
+
 var resolveFileCmd = &cobra.Command{
 	Use:   "resolve-file [options] <space/key> <output path>",
 	Short: "Reads a file at space/key and saves it to disk",
@@ -39,10 +66,34 @@ func resolveFileFunc(cmd *cobra.Command, args []string) error {
 	defer f.Close()
 
 	cli := client.New(uri, requestTimeout)
-	if err := tree.Download(context.Background(), cli, args[0], f); err != nil {
+	clis := make([]client.Client, len(downloadEndpoints))
+	for i, endpoint := range downloadEndpoints {
+		clis[i] = client.New(endpoint, requestTimeout)
+	}
+	// Encrypted files are decrypted with our key
+	decrypt := tree.WithDecryptionKey(keyLoader())
+	var size uint64
+	if err := tree.Download(context.Background(), cli, args[0], f, tree.WithProgress(func(e *tree.Event) {
+		if !e.Index {
+			size += uint64(e.Size)
+		}
+		printEvent(e)
+	}), tree.WithWorkers(downloadWorkers), tree.WithRetries(downloadRetries), tree.WithClients(clis...), decrypt); err != nil {
 		return err
 	}
 
-	color.Green("resolved file %s and stored at %s", args[0], filePath)
-	return nil
+	res := &fileResult{Path: args[0], File: filePath, Size: size}
+	r, err := tree.Stat(context.Background(), cli, args[0], decrypt)
+	if err != nil {
+		return err
+	}
+	if r.Meta != nil {
+		res.Name, res.ContentType, res.SHA256 = r.Meta.Name, r.Meta.ContentType, r.Meta.SHA256
+	}
+	return printResult(res, func() {
+		color.Green("resolved file %s and stored at %s", args[0], filePath)
+		if r.Meta != nil {
+			color.Cyan("name=%q content type=%q sha256=%s", r.Meta.Name, r.Meta.ContentType, r.Meta.SHA256)
+		}
+	})
 }
@@ -11,6 +11,7 @@ import (
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 
+	"github.com/ava-labs/spacesvm/chain"
 	"github.com/ava-labs/spacesvm/client"
 )
 
@@ -25,18 +26,38 @@ func resolveFunc(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("expected exactly 1 argument, got %d", len(args))
 	}
 	cli := client.New(uri, requestTimeout)
-	_, v, vmeta, err := cli.Resolve(context.Background(), args[0])
+	exists, v, vmeta, err := cli.Resolve(context.Background(), args[0])
 	if err != nil {
 		return err
 	}
 
-	color.Yellow("%s=>%q", args[0], v)
-	hr, err := json.Marshal(vmeta)
-	if err != nil {
-		return err
+	// Values written with --encrypt-to are decrypted with our key
+	sealed := client.IsSealed(v)
+	if sealed {
+		priv, err := loadKey()
+		if err != nil {
+			return err
+		}
+		v, err = client.Open(v, priv)
+		if err != nil {
+			return err
+		}
 	}
-	color.Yellow("Metadata: %s", string(hr))
 
-	color.Green("resolved %s", args[0])
-	return nil
+	res := &struct {
+		Path      string           `json:"path"`
+		Exists    bool             `json:"exists"`
+		Value     []byte           `json:"value"`
+		Sealed    bool             `json:"sealed,omitempty"`
+		ValueMeta *chain.ValueMeta `json:"valueMeta"`
+	}{args[0], exists, v, sealed, vmeta}
+	return printResult(res, func() {
+		if sealed {
+			color.Cyan("decrypted sealed value")
+		}
+		color.Yellow("%s=>%q", args[0], v)
+		hr, _ := json.Marshal(vmeta)
+		color.Yellow("Metadata: %s", string(hr))
+		color.Green("resolved %s", args[0])
+	})
 }
@@ -6,26 +6,43 @@ package cmd
 
 import (
 	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/spf13/cobra"
 )
 
 const (
-	requestTimeout = 30 * time.Second
-	fsModeWrite    = 0o600
+	fsModeWrite = 0o600
+
+	// legacyPrivateKeyFile is the plaintext key file used by default before
+	// keys were stored in the keystore.
+	legacyPrivateKeyFile = ".spaces-cli-pk"
 )
 
 var (
+	requestTimeout = 30 * time.Second
+
 	privateKeyFile string
+	keyName        string
+	keystoreDir    string
+	remoteSigner   string
+	signerAddress  string
 	uri            string
 	verbose        bool
+	outputFormat   string
 	workDir        string
 
 	rootCmd = &cobra.Command{
 		Use:        "spaces-cli",
 		Short:      "SpacesVM CLI",
 		SuggestFor: []string{"spaces-cli", "spacescli", "spacesctl"},
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkOutput(cmd); err != nil {
+				return err
+			}
+			return applyProfile(cmd)
+		},
 	}
 )
 
@@ -35,6 +52,10 @@ func init() {
 		panic(err)
 	}
 	workDir = p
+	home, err := os.UserHomeDir()
+	if err != nil {
+		home = workDir
+	}
 
 	cobra.EnablePrefixMatching = true
 	rootCmd.AddCommand(
@@ -42,6 +63,7 @@ func init() {
 		genesisCmd,
 		claimCmd,
 		lifelineCmd,
+		subscribeCmd,
 		setCmd,
 		deleteCmd,
 		resolveCmd,
@@ -52,15 +74,60 @@ func init() {
 		setFileCmd,
 		resolveFileCmd,
 		deleteFileCmd,
+		gcCmd,
+		setDirCmd,
+		resolveDirCmd,
 		networkCmd,
 		ownedCmd,
+		watchCmd,
+		keyCmd,
+		txCmd,
+		profileCmd,
+		devnetCmd,
+	)
+
+	rootCmd.PersistentFlags().StringVar(
+		&configFile,
+		"config",
+		filepath.Join(home, ".spaces-cli", "config.yaml"),
+		"config file with named profiles",
+	)
+	rootCmd.PersistentFlags().StringVar(
+		&profileName,
+		"profile",
+		"",
+		"name of the profile to use (defaults to the current profile of the config)",
 	)
 
 	rootCmd.PersistentFlags().StringVar(
 		&privateKeyFile,
 		"private-key-file",
-		".spaces-cli-pk",
-		"private key file path",
+		"",
+		"plaintext private key file path (overrides --key, insecure)",
+	)
+	rootCmd.PersistentFlags().StringVar(
+		&keyName,
+		"key",
+		"default",
+		"name of the keystore key",
+	)
+	rootCmd.PersistentFlags().StringVar(
+		&keystoreDir,
+		"keystore",
+		filepath.Join(home, ".spaces-cli", "keystore"),
+		"keystore directory",
+	)
+	rootCmd.PersistentFlags().StringVar(
+		&remoteSigner,
+		"remote-signer",
+		"",
+		"URL of a remote signer (overrides --key and --private-key-file)",
+	)
+	rootCmd.PersistentFlags().StringVar(
+		&signerAddress,
+		"signer-address",
+		"",
+		"address to request signatures for from the remote signer",
 	)
 	rootCmd.PersistentFlags().StringVar(
 		&uri,
@@ -74,6 +141,12 @@ func init() {
 		false,
 		"Print verbose information about operations",
 	)
+	rootCmd.PersistentFlags().StringVar(
+		&outputFormat,
+		"output",
+		outputText,
+		"output format (text or json)",
+	)
 }
 
 func Execute() error {
@@ -0,0 +1,116 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/ava-labs/spacesvm/client"
+	"github.com/ava-labs/spacesvm/parser"
+	"github.com/ava-labs/spacesvm/tree"
+)
+
+func init() {
+	setDirCmd.PersistentFlags().IntVar(
+		&uploadWorkers,
+		"workers",
+		tree.DefaultWorkers,
+		"number of chunks signed and issued concurrently",
+	)
+	setDirCmd.PersistentFlags().Uint64Var(
+		&uploadBudget,
+		"budget",
+		0,
+		"load units of chunks that may be unconfirmed at once (defaults to 4 blocks worth)",
+	)
+	setDirCmd.PersistentFlags().StringSliceVar(
+		&encryptTo,
+		"encrypt-to",
+		nil,
+		"public keys (hex) the files are encrypted to (see \"spaces-cli key public\")",
+	)
+	setDirCmd.PersistentFlags().StringVar(
+		&compression,
+		"compress",
+		"",
+		"compress each chunk before it is written (\"gzip\") to reduce its cost",
+	)
+	setDirCmd.PersistentFlags().BoolVar(
+		&contentDefined,
+		"cdc",
+		false,
+		"split files into content-defined chunks and skip chunks that already exist in the space",
+	)
+}
+
+var setDirCmd = &cobra.Command{
+	Use:   "set-dir [options] <space> <directory path>",
+	Short: "Writes a directory to the given space",
+	Long: `
+Writes every regular file and directory under a directory to the given space
+(with the mode and modification time of each entry). Chunks shared by several
+files are only written once. Symbolic links and other special files are
+skipped.
+
+$ spaces-cli set-dir patrick ./public
+`,
+	RunE: setDirFunc,
+}
+
+func setDirFunc(cmd *cobra.Command, args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("expected exactly 2 arguments, got %d", len(args))
+	}
+	space := args[0]
+	if err := parser.CheckContents(space); err != nil {
+		return fmt.Errorf("%w: failed to parse space", err)
+	}
+	dir := args[1]
+	if info, err := os.Stat(dir); err != nil {
+		return fmt.Errorf("%w: directory is not accessible", err)
+	} else if !info.IsDir() {
+		return fmt.Errorf("%s: %w", dir, tree.ErrNotDir)
+	}
+
+	signer, err := loadSigner()
+	if err != nil {
+		return err
+	}
+	recipients, err := loadRecipients()
+	if err != nil {
+		return err
+	}
+
+	cli := client.New(uri, requestTimeout)
+	if err := checkNetwork(context.Background(), cli); err != nil {
+		return err
+	}
+	g, err := cli.Rules(context.Background())
+	if err != nil {
+		return err
+	}
+
+	var totalCost, totalSaved uint64
+	opts := append(uploadOpts(recipients), tree.WithProgress(func(e *tree.Event) {
+		if e.Op != tree.EventSkip {
+			totalCost, totalSaved = e.TotalCost, e.TotalSaved
+		}
+		printEvent(e)
+	}))
+	path, err := tree.UploadDir(context.Background(), cli, signer, space, dir, int(g.MaxValueSize), opts...)
+	if err != nil {
+		return err
+	}
+
+	res := &fileResult{Path: path, Dir: dir, TotalCost: totalCost, Saved: totalSaved}
+	return printResult(res, func() {
+		color.Green("uploaded directory %s from %s", path, dir)
+		printSaved(totalCost, totalSaved)
+	})
+}
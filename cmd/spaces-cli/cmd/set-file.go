@@ -5,9 +5,13 @@ package cmd
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
@@ -17,14 +21,89 @@ import (
 	"github.com/ava-labs/spacesvm/tree"
 )
 
+var (
+	uploadWorkers  int
+	uploadBudget   uint64
+	uploadResume   bool
+	uploadManifest string
+	contentType    string
+	compression    string
+	contentDefined bool
+)
+
+func init() {
+	setFileCmd.PersistentFlags().IntVar(
+		&uploadWorkers,
+		"workers",
+		tree.DefaultWorkers,
+		"number of chunks signed and issued concurrently",
+	)
+	setFileCmd.PersistentFlags().Uint64Var(
+		&uploadBudget,
+		"budget",
+		0,
+		"load units of chunks that may be unconfirmed at once (defaults to 4 blocks worth)",
+	)
+	setFileCmd.PersistentFlags().BoolVar(
+		&uploadResume,
+		"resume",
+		false,
+		"resume an interrupted upload of the same file from its manifest",
+	)
+	setFileCmd.PersistentFlags().StringVar(
+		&uploadManifest,
+		"manifest",
+		"",
+		"manifest recording the progress of the upload (defaults to a file in the uploads directory next to --config)",
+	)
+	setFileCmd.PersistentFlags().StringVar(
+		&contentType,
+		"content-type",
+		"",
+		"content type recorded with the file (detected from its extension or contents by default)",
+	)
+	setFileCmd.PersistentFlags().StringSliceVar(
+		&encryptTo,
+		"encrypt-to",
+		nil,
+		"public keys (hex) the files are encrypted to (see \"spaces-cli key public\")",
+	)
+	setFileCmd.PersistentFlags().StringVar(
+		&compression,
+		"compress",
+		"",
+		"compress each chunk before it is written (\"gzip\") to reduce its cost",
+	)
+	setFileCmd.PersistentFlags().BoolVar(
+		&contentDefined,
+		"cdc",
+		false,
+		"split files into content-defined chunks and skip chunks that already exist in the space",
+	)
+}
+
 var setFileCmd = &cobra.Command{
 	Use:   "set-file [options] <space/key> <file path>",
 	Short: "Writes a file to the given space",
-	RunE:  setFileFunc,
+	Long: `
+Writes a file to the given space in content-addressed chunks. The progress of
+the upload is recorded in a manifest until it completes. If the upload is
+interrupted, run the same command with --resume to only issue the chunks that
+do not exist yet:
+
+$ spaces-cli set-file patrick ~/Downloads/video.mp4
+$ spaces-cli set-file patrick ~/Downloads/video.mp4 --resume
+
+With --cdc, chunk boundaries depend on the contents of the file, so uploading
+a new version of a file only issues the chunks that changed:
+
+$ spaces-cli set-file patrick ./build/nightly.tar --cdc
+`,
+	RunE: setFileFunc,
 }
 
 func setFileFunc(cmd *cobra.Command, args []string) error {
-	priv, err := crypto.LoadECDSA(privateKeyFile)
+	signer, err := loadSigner()
 	if err != nil {
 		return err
 	}
@@ -35,20 +114,65 @@ func setFileFunc(cmd *cobra.Command, args []string) error {
 	}
 	defer f.Close()
 
+	recipients, err := loadRecipients()
+	if err != nil {
+		return err
+	}
+
 	cli := client.New(uri, requestTimeout)
-	g, err := cli.Genesis(context.Background())
+	if err := checkNetwork(context.Background(), cli); err != nil {
+		return err
+	}
+	g, err := cli.Rules(context.Background())
+	if err != nil {
+		return err
+	}
+
+	manifest, err := getUploadManifest(space, f.Name())
 	if err != nil {
 		return err
 	}
 
 	// TODO: protect against overflow
-	path, err := tree.Upload(context.Background(), cli, priv, space, f, int(g.MaxValueSize))
+	var totalCost, totalSaved uint64
+	opts := append(uploadOpts(recipients),
+		tree.WithProgress(func(e *tree.Event) {
+			if e.Op != tree.EventSkip {
+				totalCost, totalSaved = e.TotalCost, e.TotalSaved
+			}
+			printEvent(e)
+		}),
+		tree.WithManifest(manifest),
+		tree.WithContentType(contentType),
+	)
+	path, err := tree.Upload(context.Background(), cli, signer, space, f, int(g.MaxValueSize), opts...)
 	if err != nil {
 		return err
 	}
+	if err := os.Remove(manifest); err != nil {
+		return err
+	}
+
+	res := &fileResult{Path: path, File: f.Name(), TotalCost: totalCost, Saved: totalSaved}
+	return printResult(res, func() {
+		color.Green("uploaded file %s from %s", path, f.Name())
+		printSaved(totalCost, totalSaved)
+	})
+}
 
-	color.Green("uploaded file %s from %s", path, f.Name())
-	return nil
+// uploadOpts returns the options shared by set-file and set-dir.
+func uploadOpts(recipients []*ecdsa.PublicKey) []tree.OpOption {
+	opts := []tree.OpOption{
+		tree.WithWorkers(uploadWorkers),
+		tree.WithUploadBudget(uploadBudget),
+		tree.WithTxOptions(client.WithMaxFee(maxFee)),
+		tree.WithEncryption(recipients...),
+		tree.WithCompression(compression),
+	}
+	if contentDefined {
+		opts = append(opts, tree.WithContentDefinedChunking(0))
+	}
+	return opts
 }
 
 func getSetFileOp(args []string) (space string, f *os.File, err error) {
@@ -73,3 +197,28 @@ func getSetFileOp(args []string) (space string, f *os.File, err error) {
 
 	return spaceKey, f, nil
 }
+
+// getUploadManifest returns the path of the manifest of the upload of
+// [filePath] to [space]. Unless --resume is set, any previous manifest is
+// removed.
+func getUploadManifest(space string, filePath string) (string, error) {
+	manifest := uploadManifest
+	if len(manifest) == 0 {
+		abs, err := filepath.Abs(filePath)
+		if err != nil {
+			return "", err
+		}
+		id := crypto.Keccak256([]byte(uri + "|" + space + "|" + abs))
+		manifest = filepath.Join(filepath.Dir(configFile), "uploads", common.Bytes2Hex(id)+".json")
+	}
+	if uploadResume {
+		if _, err := os.Stat(manifest); err != nil {
+			return "", fmt.Errorf("%w: no upload to resume", err)
+		}
+		return manifest, nil
+	}
+	if err := os.Remove(manifest); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+	return manifest, nil
+}
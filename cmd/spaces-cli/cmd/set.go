@@ -7,8 +7,6 @@ import (
 	"context"
 	"fmt"
 
-	"github.com/ethereum/go-ethereum/crypto"
-	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 
 	"github.com/ava-labs/spacesvm/chain"
@@ -16,6 +14,17 @@ import (
 	"github.com/ava-labs/spacesvm/parser"
 )
 
+var encryptTo []string
+
+func init() {
+	setCmd.PersistentFlags().StringSliceVar(
+		&encryptTo,
+		"encrypt-to",
+		nil,
+		"public keys (hex) the value is encrypted to (see \"spaces-cli key public\")",
+	)
+}
+
 var setCmd = &cobra.Command{
 	Use:   "set [options] <space/key> <value>",
 	Short: "Writes a key-value pair for the given space",
@@ -54,12 +63,19 @@ $ spaces-cli set hello.avax/foo "hello world" --private-key-file=.different-key
 <<COMMENT
 error
 COMMENT
+
+# encrypts the value so that only the owners of the given public keys can
+# read it ("spaces-cli resolve" decrypts it automatically)
+$ spaces-cli set hello.avax/config "secret" --encrypt-to 0x02ab...,0x03cd...
+<<COMMENT
+success
+COMMENT
 `,
 	RunE: setFunc,
 }
 
 func setFunc(cmd *cobra.Command, args []string) error {
-	priv, err := crypto.LoadECDSA(privateKeyFile)
+	signer, err := loadSigner()
 	if err != nil {
 		return err
 	}
@@ -68,6 +84,16 @@ func setFunc(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	if len(encryptTo) > 0 {
+		recipients, err := loadRecipients()
+		if err != nil {
+			return err
+		}
+		val, err = client.Seal(val, recipients...)
+		if err != nil {
+			return err
+		}
+	}
 
 	utx := &chain.SetTx{
 		BaseTx: &chain.BaseTx{},
@@ -77,17 +103,11 @@ func setFunc(cmd *cobra.Command, args []string) error {
 	}
 
 	cli := client.New(uri, requestTimeout)
-	opts := []client.OpOption{client.WithPollTx()}
-	if verbose {
-		opts = append(opts, client.WithInfo(space))
-		opts = append(opts, client.WithBalance())
-	}
-	if _, _, err := client.SignIssueRawTx(context.Background(), cli, utx, priv, opts...); err != nil {
+	res, err := issueTx(context.Background(), cli, utx, signer, space)
+	if err != nil {
 		return err
 	}
-
-	color.Green("set %s in %s", key, space)
-	return nil
+	return printTx(res, signer, "set %s in %s", key, space)
 }
 
 func getSetOp(args []string) (space string, key string, val []byte, err error) {
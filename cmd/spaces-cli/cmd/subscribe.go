@@ -0,0 +1,57 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ava-labs/spacesvm/chain"
+	"github.com/ava-labs/spacesvm/client"
+)
+
+var subscribeCmd = &cobra.Command{
+	Use:   "subscribe [options] <space> <units> <budget>",
+	Short: "Automatically renews a space from a pre-funded budget (0 units cancels)",
+	RunE:  subscribeFunc,
+}
+
+func subscribeFunc(cmd *cobra.Command, args []string) error {
+	signer, err := loadSigner()
+	if err != nil {
+		return err
+	}
+
+	if len(args) != 3 {
+		return fmt.Errorf("expected exactly 3 arguments, got %d", len(args))
+	}
+	space, units, err := getLifelineOp(args[:2])
+	if err != nil {
+		return err
+	}
+	budget, err := strconv.ParseUint(args[2], 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: failed to parse budget", err)
+	}
+
+	utx := &chain.SubscribeTx{
+		BaseTx: &chain.BaseTx{},
+		Space:  space,
+		Units:  units,
+		Budget: budget,
+	}
+
+	cli := client.New(uri, requestTimeout)
+	res, err := issueTx(context.Background(), cli, utx, signer, space)
+	if err != nil {
+		return err
+	}
+	if units == 0 {
+		return printTx(res, signer, "cancelled subscription for %s", space)
+	}
+	return printTx(res, signer, "subscribed %s to renewals of %d units (budget +%d)", space, units, budget)
+}
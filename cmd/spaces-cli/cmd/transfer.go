@@ -9,8 +9,6 @@ import (
 	"strconv"
 
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/crypto"
-	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 
 	"github.com/ava-labs/spacesvm/chain"
@@ -24,7 +22,7 @@ var transferCmd = &cobra.Command{
 }
 
 func transferFunc(cmd *cobra.Command, args []string) error {
-	priv, err := crypto.LoadECDSA(privateKeyFile)
+	signer, err := loadSigner()
 	if err != nil {
 		return err
 	}
@@ -41,16 +39,11 @@ func transferFunc(cmd *cobra.Command, args []string) error {
 	}
 
 	cli := client.New(uri, requestTimeout)
-	opts := []client.OpOption{client.WithPollTx()}
-	if verbose {
-		opts = append(opts, client.WithBalance())
-	}
-	if _, _, err := client.SignIssueRawTx(context.Background(), cli, utx, priv, opts...); err != nil {
+	res, err := issueTx(context.Background(), cli, utx, signer, "")
+	if err != nil {
 		return err
 	}
-
-	color.Green("transferred %d to %s", units, to.Hex())
-	return nil
+	return printTx(res, signer, "transferred %d to %s", units, to.Hex())
 }
 
 func getTransferOp(args []string) (to common.Address, units uint64, err error) {
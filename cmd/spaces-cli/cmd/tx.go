@@ -0,0 +1,242 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/ava-labs/spacesvm/chain"
+	"github.com/ava-labs/spacesvm/client"
+	"github.com/ava-labs/spacesvm/tdata"
+)
+
+func init() {
+	txCmd.AddCommand(
+		txBuildCmd,
+		txSignCmd,
+		txSendCmd,
+	)
+}
+
+// txFile is written by "tx build" and "tx sign" and read by "tx sign" and
+// "tx send".
+type txFile struct {
+	TypedData  *tdata.TypedData `json:"typedData"`
+	TotalCost  uint64           `json:"totalCost"`
+	ValidUntil int64            `json:"validUntil"`
+	Signature  hexutil.Bytes    `json:"signature,omitempty"`
+}
+
+var txCmd = &cobra.Command{
+	Use:   "tx",
+	Short: "Builds, signs (offline), and sends transactions in separate steps",
+	Long: `
+Splits issuing a transaction into steps so that signing can happen on a
+machine without network access:
+
+$ spaces-cli tx build input.json unsigned.json   # online
+$ spaces-cli tx sign unsigned.json signed.json   # offline
+$ spaces-cli tx send signed.json                 # online
+
+The input is a chain.Input (ex: {"type":"claim","space":"patrick"}). The
+built transaction references a recent block and must be sent before the
+reported deadline or it may be rejected.
+`,
+}
+
+var txBuildCmd = &cobra.Command{
+	Use:   "build [options] <input file (- for stdin)> <output file>",
+	Short: "Writes the unsigned EIP-712 typed data for an input",
+	RunE:  txBuildFunc,
+}
+
+func txBuildFunc(cmd *cobra.Command, args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("expected exactly 2 arguments, got %d", len(args))
+	}
+	var (
+		b   []byte
+		err error
+	)
+	if args[0] == "-" {
+		b, err = io.ReadAll(os.Stdin)
+	} else {
+		b, err = os.ReadFile(args[0])
+	}
+	if err != nil {
+		return err
+	}
+	input := new(chain.Input)
+	if err := json.Unmarshal(b, input); err != nil {
+		return fmt.Errorf("%w: failed to parse input", err)
+	}
+
+	cli := client.New(uri, requestTimeout)
+	if err := checkNetwork(context.Background(), cli); err != nil {
+		return err
+	}
+	td, cost, validUntil, err := cli.SuggestedFee(context.Background(), input)
+	if err != nil {
+		return err
+	}
+	if err := writeTxFile(args[1], &txFile{TypedData: td, TotalCost: cost, ValidUntil: validUntil}); err != nil {
+		return err
+	}
+	res := &txFileResult{File: args[1], TotalCost: cost, ValidUntil: validUntil}
+	return printResult(res, func() {
+		color.Green("wrote unsigned %s tx to %s (total cost=%d)", td.PrimaryType, args[1], cost)
+		printValidity(validUntil)
+	})
+}
+
+var txSignCmd = &cobra.Command{
+	Use:   "sign [options] <unsigned file> <output file>",
+	Short: "Signs a built transaction (does not use the network)",
+	RunE:  txSignFunc,
+}
+
+func txSignFunc(cmd *cobra.Command, args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("expected exactly 2 arguments, got %d", len(args))
+	}
+	f, err := readTxFile(args[0])
+	if err != nil {
+		return err
+	}
+	// Parse the typed data to make sure it describes a valid tx (and show
+	// the signer what they are signing)
+	utx, err := chain.ParseTypedData(f.TypedData)
+	if err != nil {
+		return err
+	}
+	// The chainID can't be checked offline but the magic protects against
+	// replays on other networks
+	if err := checkMagic(utx.GetMagic()); err != nil {
+		return err
+	}
+	if maxFee > 0 && f.TotalCost > maxFee {
+		return fmt.Errorf("%w: cost %d exceeds %d", client.ErrFeeTooHigh, f.TotalCost, maxFee)
+	}
+	b, err := json.Marshal(f.TypedData.Message)
+	if err != nil {
+		return err
+	}
+	color.Yellow("signing %s tx: %s", utx.Activity().Typ, string(b))
+
+	signer, err := loadSigner()
+	if err != nil {
+		return err
+	}
+	dh, err := tdata.DigestHash(f.TypedData)
+	if err != nil {
+		return err
+	}
+	f.Signature, err = signer.SignDigest(context.Background(), dh)
+	if err != nil {
+		return err
+	}
+	if err := writeTxFile(args[1], f); err != nil {
+		return err
+	}
+	res := &txFileResult{File: args[1], TotalCost: f.TotalCost, ValidUntil: f.ValidUntil, Signer: signer.Address().Hex()}
+	return printResult(res, func() {
+		color.Green("signed tx as %s and wrote it to %s", res.Signer, args[1])
+		printValidity(f.ValidUntil)
+	})
+}
+
+var txSendCmd = &cobra.Command{
+	Use:   "send [options] <signed file>",
+	Short: "Issues a signed transaction",
+	RunE:  txSendFunc,
+}
+
+func txSendFunc(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("expected exactly 1 argument, got %d", len(args))
+	}
+	f, err := readTxFile(args[0])
+	if err != nil {
+		return err
+	}
+	if len(f.Signature) == 0 {
+		return fmt.Errorf("%s is not signed", args[0])
+	}
+	if outputFormat == outputText {
+		printValidity(f.ValidUntil)
+	}
+
+	cli := client.New(uri, requestTimeout)
+	ctx := context.Background()
+	if err := checkNetwork(ctx, cli); err != nil {
+		return err
+	}
+	txID, err := cli.IssueTx(ctx, f.TypedData, f.Signature)
+	if err != nil {
+		return err
+	}
+	color.Yellow("issued transaction %s (now polling)", txID)
+	confirmed, err := cli.PollTx(ctx, txID)
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		return fmt.Errorf("%w: %s", client.ErrNotConfirmed, txID)
+	}
+	res := &txResult{TxID: txID, Cost: f.TotalCost}
+	return printResult(res, func() {
+		color.Green("transaction %s confirmed", txID)
+	})
+}
+
+// txFileResult is reported by "tx build" and "tx sign".
+type txFileResult struct {
+	File       string `json:"file"`
+	TotalCost  uint64 `json:"totalCost"`
+	ValidUntil int64  `json:"validUntil"`
+	Signer     string `json:"signer,omitempty"`
+}
+
+func readTxFile(path string) (*txFile, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	f := new(txFile)
+	if err := json.Unmarshal(b, f); err != nil {
+		return nil, fmt.Errorf("%w: failed to parse %s", err, path)
+	}
+	if f.TypedData == nil {
+		return nil, fmt.Errorf("%s is missing typed data", path)
+	}
+	return f, nil
+}
+
+func writeTxFile(path string, f *txFile) error {
+	b, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, b, fsModeWrite)
+}
+
+// printValidity reports how long a built tx can still be sent for.
+func printValidity(validUntil int64) {
+	deadline := time.Unix(validUntil, 0)
+	remaining := time.Until(deadline).Round(time.Second)
+	if remaining <= 0 {
+		color.Red("block ID may no longer be recent (expired %v ago), rebuild if sending fails", -remaining)
+		return
+	}
+	color.Cyan("must be sent before %v (%v remaining)", deadline, remaining)
+}
@@ -0,0 +1,232 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/fatih/color"
+	log "github.com/inconshreveable/log15"
+	"github.com/spf13/cobra"
+
+	"github.com/ava-labs/spacesvm/chain"
+	"github.com/ava-labs/spacesvm/client"
+)
+
+var (
+	watchAddress      string
+	watchInterval     time.Duration
+	watchThreshold    time.Duration
+	watchExtend       time.Duration
+	watchAutoLifeline bool
+	watchSpendingCap  uint64
+)
+
+func init() {
+	watchCmd.PersistentFlags().StringVar(
+		&watchAddress,
+		"address",
+		"",
+		"address whose spaces are watched (defaults to the address of the signer)",
+	)
+	watchCmd.PersistentFlags().DurationVar(
+		&watchInterval,
+		"interval",
+		5*time.Minute,
+		"time between checks",
+	)
+	watchCmd.PersistentFlags().DurationVar(
+		&watchThreshold,
+		"threshold",
+		72*time.Hour,
+		"warn when a space expires within this duration",
+	)
+	watchCmd.PersistentFlags().BoolVar(
+		&watchAutoLifeline,
+		"auto-lifeline",
+		false,
+		"issue a LifelineTx for spaces that expire within the threshold",
+	)
+	watchCmd.PersistentFlags().DurationVar(
+		&watchExtend,
+		"extend",
+		30*24*time.Hour,
+		"duration (from now) a space should last after an automatic lifeline",
+	)
+	watchCmd.PersistentFlags().Uint64Var(
+		&watchSpendingCap,
+		"spending-cap",
+		0,
+		"maximum units spent on automatic lifelines (required with --auto-lifeline)",
+	)
+}
+
+var watchCmd = &cobra.Command{
+	Use:   "watch [options]",
+	Short: "Monitors owned spaces and warns (or issues lifelines) before they expire",
+	Long: `Runs until interrupted, periodically checking the expiry of all spaces
+owned by an address. Logs are written to stdout as JSON (one object per line).`,
+	RunE: watchFunc,
+}
+
+type watcher struct {
+	cli    client.Client
+	log    log.Logger
+	addr   common.Address
+	signer client.Signer
+	spent  uint64
+}
+
+func watchFunc(cmd *cobra.Command, args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("expected exactly 0 arguments, got %d", len(args))
+	}
+	if watchInterval <= 0 || watchThreshold <= 0 {
+		return errors.New("interval and threshold must be positive")
+	}
+
+	w := &watcher{
+		cli: client.New(uri, requestTimeout),
+		log: log.New(),
+	}
+	lvl := log.LvlInfo
+	if verbose {
+		lvl = log.LvlDebug
+	}
+	w.log.SetHandler(log.LvlFilterHandler(lvl, log.StreamHandler(os.Stdout, log.JsonFormat())))
+	// Only emit JSON logs (the client prints human-readable progress)
+	color.Output = io.Discard
+
+	if watchAutoLifeline {
+		if watchSpendingCap == 0 {
+			return errors.New("--spending-cap must be set with --auto-lifeline")
+		}
+		if watchExtend <= watchThreshold {
+			return errors.New("--extend must be greater than --threshold")
+		}
+	}
+	if watchAutoLifeline || len(watchAddress) == 0 {
+		signer, err := loadSigner()
+		if err != nil {
+			return err
+		}
+		w.signer = signer
+		w.addr = signer.Address()
+	}
+	if len(watchAddress) > 0 {
+		if !common.IsHexAddress(watchAddress) {
+			return fmt.Errorf("invalid address %q", watchAddress)
+		}
+		// Lifelines can be issued for any space, so the payer does not need
+		// to own the watched spaces
+		w.addr = common.HexToAddress(watchAddress)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	if watchAutoLifeline {
+		if err := checkNetwork(ctx, w.cli); err != nil {
+			return err
+		}
+	}
+
+	w.log.Info("watching spaces",
+		"address", w.addr,
+		"interval", watchInterval.String(),
+		"threshold", watchThreshold.String(),
+		"autoLifeline", watchAutoLifeline,
+		"spendingCap", watchSpendingCap,
+	)
+	t := time.NewTicker(watchInterval)
+	defer t.Stop()
+	for {
+		w.check(ctx)
+		select {
+		case <-ctx.Done():
+			w.log.Info("stopping", "spent", w.spent)
+			return nil
+		case <-t.C:
+		}
+	}
+}
+
+// check warns about (and optionally renews) every space that expires within
+// [watchThreshold]. Errors are logged so that the daemon keeps running.
+func (w *watcher) check(ctx context.Context) {
+	spaces, err := w.cli.Owned(ctx, w.addr)
+	if err != nil {
+		w.log.Error("unable to fetch owned spaces", "error", err)
+		return
+	}
+	now := time.Now()
+	for _, space := range spaces {
+		info, _, err := w.cli.Info(ctx, space)
+		if err != nil {
+			w.log.Error("unable to fetch space info", "space", space, "error", err)
+			continue
+		}
+		expiry := time.Unix(int64(info.Expiry), 0)
+		remaining := expiry.Sub(now).Round(time.Second)
+		if remaining > watchThreshold {
+			w.log.Debug("space ok", "space", space, "expiry", expiry, "remaining", remaining.String())
+			continue
+		}
+		w.log.Warn("space expiring", "space", space, "expiry", expiry, "remaining", remaining.String())
+		if watchAutoLifeline {
+			w.lifeline(ctx, space, now)
+		}
+	}
+}
+
+// lifeline issues a [chain.LifelineTx] that extends [space] until
+// [watchExtend] from [now], unless it would exceed [watchSpendingCap]. The cap
+// is checked for each lifeline, so a cheaper renewal may still be issued after
+// an expensive one was skipped.
+func (w *watcher) lifeline(ctx context.Context, space string, now time.Time) {
+	target := uint64(now.Add(watchExtend).Unix())
+	p, err := w.cli.ProjectSpace(ctx, space, nil, target)
+	if err != nil {
+		w.log.Error("unable to project space", "space", space, "error", err)
+		return
+	}
+	if p.LifelineUnits == 0 {
+		return
+	}
+	price, blockCost, err := w.cli.SuggestedRawFee(ctx)
+	if err != nil {
+		w.log.Error("unable to fetch fee", "error", err)
+		return
+	}
+	// Upper bound of the cost computed by [client.SignIssueRawTx]
+	estimate := price*p.LifelineFeeUnits + blockCost
+	if w.spent+estimate > watchSpendingCap {
+		w.log.Error("lifeline skipped, would exceed spending cap",
+			"space", space, "estimate", estimate, "spent", w.spent, "spendingCap", watchSpendingCap,
+		)
+		return
+	}
+	utx := &chain.LifelineTx{
+		BaseTx: &chain.BaseTx{},
+		Space:  space,
+		Units:  p.LifelineUnits,
+	}
+	txID, cost, err := client.SignIssueRawTx(ctx, w.cli, utx, w.signer, txOptions()...)
+	if err != nil {
+		w.log.Error("unable to issue lifeline", "space", space, "error", err)
+		return
+	}
+	w.spent += cost
+	w.log.Info("issued lifeline",
+		"space", space, "txID", txID, "units", p.LifelineUnits,
+		"expiry", time.Unix(int64(target), 0), "cost", cost, "spent", w.spent,
+	)
+}
@@ -0,0 +1,92 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cmd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ethereum/go-ethereum/crypto"
+	log "github.com/inconshreveable/log15"
+
+	"github.com/ava-labs/spacesvm/chain"
+	"github.com/ava-labs/spacesvm/client"
+)
+
+// lifelineClient projects a fixed number of lifeline units per space and
+// accepts every transaction.
+type lifelineClient struct {
+	client.Client
+
+	g      *chain.Genesis
+	units  map[string]uint64
+	issued []string
+}
+
+func (c *lifelineClient) ProjectSpace(
+	_ context.Context, space string, _ []*chain.ProjectedOp, _ uint64,
+) (*chain.Projection, error) {
+	units := c.units[space]
+	return &chain.Projection{
+		LifelineUnits: units,
+		LifelineFeeUnits: (&chain.LifelineTx{
+			BaseTx: &chain.BaseTx{},
+			Space:  space,
+			Units:  units,
+		}).FeeUnits(c.g),
+	}, nil
+}
+
+func (c *lifelineClient) Rules(context.Context) (*chain.Genesis, error) { return c.g, nil }
+
+func (c *lifelineClient) Accepted(context.Context) (ids.ID, error) { return ids.GenerateTestID(), nil }
+
+func (c *lifelineClient) SuggestedRawFee(context.Context) (uint64, uint64, error) { return 1, 0, nil }
+
+func (c *lifelineClient) IssueRawTx(_ context.Context, d []byte) (ids.ID, error) {
+	tx := new(chain.Transaction)
+	if _, err := chain.Unmarshal(d, tx); err != nil {
+		return ids.Empty, err
+	}
+	c.issued = append(c.issued, tx.UnsignedTransaction.(*chain.LifelineTx).Space)
+	return ids.GenerateTestID(), nil
+}
+
+func (c *lifelineClient) PollTx(context.Context, ids.ID) (bool, error) { return true, nil }
+
+func TestWatchSpendingCap(t *testing.T) {
+	priv, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cli := &lifelineClient{
+		g:     chain.DefaultGenesis(),
+		units: map[string]uint64{"expensive": 1_000_000, "cheap": 1},
+	}
+	w := &watcher{
+		cli:    cli,
+		log:    log.New(),
+		signer: client.NewKeySigner(priv),
+	}
+	w.log.SetHandler(log.DiscardHandler())
+
+	cheap, _ := cli.ProjectSpace(context.Background(), "cheap", nil, 0)
+	defer func(c uint64, m uint64) { watchSpendingCap, maxFee = c, m }(watchSpendingCap, maxFee)
+	watchSpendingCap = 2*cheap.LifelineFeeUnits + 1
+	maxFee = 0
+
+	// Skipping the expensive lifeline must not stop cheaper ones
+	for i := 0; i < 3; i++ {
+		w.lifeline(context.Background(), "expensive", time.Now())
+		w.lifeline(context.Background(), "cheap", time.Now())
+	}
+	if len(cli.issued) != 2 || cli.issued[0] != "cheap" || cli.issued[1] != "cheap" {
+		t.Fatalf("unexpected lifelines %v", cli.issued)
+	}
+	if w.spent != 2*cheap.LifelineFeeUnits {
+		t.Fatalf("spent %d, expected %d", w.spent, 2*cheap.LifelineFeeUnits)
+	}
+}
@@ -7,14 +7,12 @@ package main
 import (
 	"os"
 
-	"github.com/fatih/color"
-
 	"github.com/ava-labs/spacesvm/cmd/spaces-cli/cmd"
 )
 
 func main() {
 	if err := cmd.Execute(); err != nil {
-		color.Red("spaces-cli failed: %v", err)
+		cmd.PrintError(err)
 		os.Exit(1)
 	}
 	os.Exit(0)
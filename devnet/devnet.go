@@ -0,0 +1,356 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package devnet runs a local network of in-process SpacesVMs (without
+// avalanchego) for development and testing. Blocks are produced by a simple
+// driver that lets the node that signals pending transactions build a block
+// and then has every node verify and accept it.
+package devnet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/avalanchego/api/metrics"
+	"github.com/ava-labs/avalanchego/database/manager"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow"
+	"github.com/ava-labs/avalanchego/snow/consensus/snowman"
+	"github.com/ava-labs/avalanchego/snow/engine/common"
+	"github.com/ava-labs/avalanchego/utils/set"
+	avago_version "github.com/ava-labs/avalanchego/version"
+	log "github.com/inconshreveable/log15"
+
+	"github.com/ava-labs/spacesvm/vm"
+)
+
+const (
+	networkID = 1337
+
+	readHeaderTimeout = 10 * time.Second
+)
+
+type Config struct {
+	Nodes int
+
+	Genesis  []byte
+	Upgrades []byte
+	// Config of each VM (defaults are used if empty)
+	VMConfig []byte
+
+	// Node i listens on [Host]:[BasePort]+i (or a random port if [BasePort]
+	// is 0)
+	Host     string
+	BasePort int
+}
+
+type Node struct {
+	NodeID ids.NodeID
+	// URL of the VM endpoints (the public API is served at URL+"/public")
+	URL string
+	VM  *vm.VM
+
+	ctx      *snow.Context
+	toEngine chan common.Message
+	server   *http.Server
+}
+
+type Network struct {
+	SubnetID ids.ID
+	ChainID  ids.ID
+	Nodes    []*Node
+
+	// [signaled] is set for every node that signals pending transactions
+	// (and [notify] is sent to). Signals are never dropped because a node
+	// does not signal again until it builds a block.
+	mu       sync.Mutex
+	signaled []bool
+	notify   chan struct{}
+
+	// Closed once all nodes are initialized (gossip is dropped before)
+	ready chan struct{}
+	stop  chan struct{}
+}
+
+// New initializes [Config.Nodes] VMs with the same genesis and serves their
+// APIs. Blocks are not produced until [Run] is called.
+func New(ctx context.Context, cfg *Config) (*Network, error) {
+	if cfg.Nodes < 1 {
+		return nil, ErrNoNodes
+	}
+	n := &Network{
+		SubnetID: ids.GenerateTestID(),
+		ChainID:  ids.GenerateTestID(),
+		Nodes:    make([]*Node, cfg.Nodes),
+		signaled: make([]bool, cfg.Nodes),
+		notify:   make(chan struct{}, 1),
+		ready:    make(chan struct{}),
+		stop:     make(chan struct{}),
+	}
+	for i := range n.Nodes {
+		node, err := n.newNode(ctx, cfg, i)
+		if err != nil {
+			_ = n.Shutdown(ctx)
+			return nil, err
+		}
+		n.Nodes[i] = node
+	}
+	close(n.ready)
+	return n, nil
+}
+
+func (n *Network) newNode(ctx context.Context, cfg *Config, i int) (*Node, error) {
+	node := &Node{
+		NodeID: ids.GenerateTestNodeID(),
+		// Buffered in the same way as the channel avalanchego provides
+		toEngine: make(chan common.Message, 1),
+	}
+	node.ctx = &snow.Context{
+		NetworkID: networkID,
+		SubnetID:  n.SubnetID,
+		ChainID:   n.ChainID,
+		NodeID:    node.NodeID,
+		Metrics:   metrics.NewOptionalGatherer(),
+	}
+	node.VM = &vm.VM{}
+	if err := node.VM.Initialize(
+		ctx,
+		node.ctx,
+		manager.NewMemDB(avago_version.CurrentDatabase),
+		cfg.Genesis,
+		cfg.Upgrades,
+		cfg.VMConfig,
+		node.toEngine,
+		nil,
+		&appSender{network: n, from: node.NodeID},
+	); err != nil {
+		return nil, fmt.Errorf("%w: failed to initialize node %d", err, i)
+	}
+	// There is nothing to bootstrap from, so every node starts in normal
+	// operation (like avalanchego does once bootstrapping finishes)
+	if err := node.VM.SetState(ctx, snow.NormalOp); err != nil {
+		_ = node.VM.Shutdown(ctx)
+		return nil, fmt.Errorf("%w: failed to start node %d", err, i)
+	}
+
+	handlers, err := node.VM.CreateHandlers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	mux := http.NewServeMux()
+	for endpoint, h := range handlers {
+		mux.Handle(endpoint, lockHandler(&node.ctx.Lock, h))
+	}
+	port := 0
+	if cfg.BasePort > 0 {
+		port = cfg.BasePort + i
+	}
+	ln, err := net.Listen("tcp", net.JoinHostPort(cfg.Host, fmt.Sprint(port)))
+	if err != nil {
+		_ = node.VM.Shutdown(ctx)
+		return nil, err
+	}
+	node.URL = "http://" + ln.Addr().String()
+	node.server = &http.Server{Handler: mux, ReadHeaderTimeout: readHeaderTimeout}
+	go func() {
+		if err := node.server.Serve(ln); !errors.Is(err, http.ErrServerClosed) {
+			log.Error("devnet server stopped", "node", i, "error", err)
+		}
+	}()
+	go func() {
+		for {
+			select {
+			case <-node.toEngine:
+				n.mu.Lock()
+				n.signaled[i] = true
+				n.mu.Unlock()
+				select {
+				case n.notify <- struct{}{}:
+				default:
+				}
+			case <-n.stop:
+				return
+			}
+		}
+	}()
+	return node, nil
+}
+
+// lockHandler holds [l] while [h] serves a request (as avalanchego does).
+func lockHandler(l *sync.RWMutex, h *common.HTTPHandler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch h.LockOptions {
+		case common.WriteLock:
+			l.Lock()
+			defer l.Unlock()
+		case common.ReadLock:
+			l.RLock()
+			defer l.RUnlock()
+		}
+		h.Handler.ServeHTTP(w, r)
+	})
+}
+
+// Run produces blocks until [ctx] is done.
+func (n *Network) Run(ctx context.Context) {
+	for {
+		select {
+		case <-n.notify:
+			for i := range n.Nodes {
+				n.mu.Lock()
+				signaled := n.signaled[i]
+				n.signaled[i] = false
+				n.mu.Unlock()
+				if !signaled {
+					continue
+				}
+				err := n.produce(ctx, i)
+				switch {
+				case errors.Is(err, ErrVerifyBlock):
+					log.Warn("unable to produce block", "node", i, "error", err)
+				case err != nil:
+					// Nodes often have no txs left after another node built
+					log.Debug("unable to produce block", "node", i, "error", err)
+				}
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// produce builds a block on node [i], verifies it on every node, and then
+// accepts it everywhere. If any node fails to verify the block, it is rejected
+// by the nodes that verified it.
+func (n *Network) produce(ctx context.Context, i int) error {
+	leader := n.Nodes[i]
+	leader.ctx.Lock.Lock()
+	blk, err := leader.VM.BuildBlock(ctx)
+	leader.ctx.Lock.Unlock()
+	if err != nil {
+		return err
+	}
+
+	blks := make([]snowman.Block, len(n.Nodes))
+	for j, node := range n.Nodes {
+		node.ctx.Lock.Lock()
+		b := blk
+		if j != i {
+			b, err = node.VM.ParseBlock(ctx, blk.Bytes())
+		}
+		if err == nil {
+			err = b.Verify(ctx)
+		}
+		node.ctx.Lock.Unlock()
+		if err != nil {
+			n.reject(ctx, blks[:j])
+			return fmt.Errorf("%w: %s on node %d: %v", ErrVerifyBlock, blk.ID(), j, err)
+		}
+		blks[j] = b
+	}
+	for j, node := range n.Nodes {
+		node.ctx.Lock.Lock()
+		err := node.VM.SetPreference(ctx, blks[j].ID())
+		if err == nil {
+			err = blks[j].Accept(ctx)
+		}
+		node.ctx.Lock.Unlock()
+		if err != nil {
+			return fmt.Errorf("%w: failed to accept %s on node %d", err, blk.ID(), j)
+		}
+	}
+	log.Info("accepted block", "leader", i, "id", blk.ID(), "height", blk.Height())
+	return nil
+}
+
+func (n *Network) reject(ctx context.Context, blks []snowman.Block) {
+	for j, b := range blks {
+		node := n.Nodes[j]
+		node.ctx.Lock.Lock()
+		if err := b.Reject(ctx); err != nil {
+			log.Error("unable to reject block", "node", j, "id", b.ID(), "error", err)
+		}
+		node.ctx.Lock.Unlock()
+	}
+}
+
+// Shutdown stops all servers and VMs.
+func (n *Network) Shutdown(ctx context.Context) error {
+	close(n.stop)
+	var errs []error
+	for _, node := range n.Nodes {
+		if node == nil {
+			continue
+		}
+		if err := node.server.Shutdown(ctx); err != nil {
+			errs = append(errs, err)
+		}
+		if err := node.VM.Shutdown(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+var _ common.AppSender = (*appSender)(nil)
+
+// appSender delivers gossip to every other node of the network. Delivery is
+// asynchronous because the sending node may hold its lock.
+type appSender struct {
+	network *Network
+	from    ids.NodeID
+}
+
+func (a *appSender) gossip(b []byte, to func(ids.NodeID) bool) {
+	select {
+	case <-a.network.ready:
+	default:
+		return
+	}
+	for _, node := range a.network.Nodes {
+		if node == nil || node.NodeID == a.from || !to(node.NodeID) {
+			continue
+		}
+		go func(node *Node) {
+			node.ctx.Lock.Lock()
+			defer node.ctx.Lock.Unlock()
+			if err := node.VM.AppGossip(context.Background(), a.from, b); err != nil {
+				log.Debug("unable to deliver gossip", "node", node.NodeID, "error", err)
+			}
+		}(node)
+	}
+}
+
+func (a *appSender) SendAppGossip(_ context.Context, b []byte) error {
+	a.gossip(b, func(ids.NodeID) bool { return true })
+	return nil
+}
+
+func (a *appSender) SendAppGossipSpecific(_ context.Context, nodeIDs set.Set[ids.NodeID], b []byte) error {
+	a.gossip(b, nodeIDs.Contains)
+	return nil
+}
+
+func (*appSender) SendAppRequest(context.Context, set.Set[ids.NodeID], uint32, []byte) error {
+	return nil
+}
+
+func (*appSender) SendAppResponse(context.Context, ids.NodeID, uint32, []byte) error {
+	return nil
+}
+
+func (*appSender) SendCrossChainAppRequest(context.Context, ids.ID, uint32, []byte) error {
+	return nil
+}
+
+func (*appSender) SendCrossChainAppResponse(context.Context, ids.ID, uint32, []byte) error {
+	return nil
+}
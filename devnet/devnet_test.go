@@ -0,0 +1,86 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package devnet
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/ava-labs/spacesvm/chain"
+	"github.com/ava-labs/spacesvm/client"
+)
+
+func TestNew(t *testing.T) {
+	if _, err := New(context.Background(), &Config{}); !errors.Is(err, ErrNoNodes) {
+		t.Fatalf("expected %v, got %v", ErrNoNodes, err)
+	}
+}
+
+func TestNetwork(t *testing.T) {
+	priv, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := chain.DefaultGenesis()
+	g.Magic = 7
+	g.CustomAllocation = []*chain.CustomAllocation{
+		{Address: crypto.PubkeyToAddress(priv.PublicKey), Balance: 10_000_000},
+	}
+	genesis, err := json.Marshal(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	n, err := New(ctx, &Config{Nodes: 2, Genesis: genesis, Host: "127.0.0.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := n.Shutdown(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	go n.Run(ctx)
+
+	// Issue to the first node and read from the second
+	cli := client.New(n.Nodes[0].URL, 10*time.Second)
+	utx := &chain.ClaimTx{BaseTx: &chain.BaseTx{}, Space: "devnet"}
+	if _, _, err := client.SignIssueRawTx(ctx, cli, utx, client.NewKeySigner(priv), client.WithPollTx()); err != nil {
+		t.Fatal(err)
+	}
+	cli2 := client.New(n.Nodes[1].URL, 10*time.Second)
+	for {
+		claimed, err := cli2.Claimed(ctx, "devnet")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if claimed {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			t.Fatal(ctx.Err())
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+	for i, node := range n.Nodes {
+		if _, err := node.VM.HealthCheck(ctx); err != nil {
+			t.Fatalf("node %d is unhealthy: %v", i, err)
+		}
+		id, err := node.VM.LastAccepted(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, _ := n.Nodes[0].VM.LastAccepted(ctx); id != expected {
+			t.Fatalf("node %d accepted %s, expected %s", i, id, expected)
+		}
+	}
+}
@@ -0,0 +1,11 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package devnet
+
+import "errors"
+
+var (
+	ErrNoNodes     = errors.New("at least one node is required")
+	ErrVerifyBlock = errors.New("block failed verification")
+)
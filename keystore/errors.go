@@ -0,0 +1,12 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package keystore
+
+import "errors"
+
+var (
+	ErrKeyExists  = errors.New("key already exists")
+	ErrKeyMissing = errors.New("key does not exist")
+	ErrInvalidKey = errors.New("invalid key file")
+)
@@ -0,0 +1,173 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package keystore stores named private keys encrypted with a passphrase
+// (scrypt + AES-128-CTR) using the go-ethereum keystore v3 JSON format.
+package keystore
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
+
+	"github.com/ava-labs/spacesvm/parser"
+)
+
+const (
+	keyExt = ".json"
+
+	dirMode  = 0o700
+	fileMode = 0o600
+)
+
+// Keystore is a directory of encrypted keys. Each key is stored in
+// "<name>.json" and can be used with any tool that reads the keystore v3
+// format (such as geth).
+type Keystore struct {
+	dir string
+
+	scryptN int
+	scryptP int
+}
+
+func New(dir string) *Keystore {
+	return &Keystore{
+		dir:     dir,
+		scryptN: keystore.StandardScryptN,
+		scryptP: keystore.StandardScryptP,
+	}
+}
+
+type Entry struct {
+	Name    string         `json:"name"`
+	Address common.Address `json:"address"`
+}
+
+// Path returns the file [name] is stored in.
+func (ks *Keystore) Path(name string) string {
+	return filepath.Join(ks.dir, name+keyExt)
+}
+
+// Create generates a new key and stores it as [name].
+func (ks *Keystore) Create(name string, passphrase string) (*ecdsa.PrivateKey, error) {
+	priv, err := crypto.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	if err := ks.Import(name, priv, passphrase); err != nil {
+		return nil, err
+	}
+	return priv, nil
+}
+
+// Import stores [priv] as [name]. It errors if [name] already exists.
+func (ks *Keystore) Import(name string, priv *ecdsa.PrivateKey, passphrase string) error {
+	if err := parser.CheckContents(name); err != nil {
+		return fmt.Errorf("%w: invalid key name", err)
+	}
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return err
+	}
+	b, err := keystore.EncryptKey(&keystore.Key{
+		Id:         id,
+		Address:    crypto.PubkeyToAddress(priv.PublicKey),
+		PrivateKey: priv,
+	}, passphrase, ks.scryptN, ks.scryptP)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(ks.dir, dirMode); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(ks.Path(name), os.O_WRONLY|os.O_CREATE|os.O_EXCL, fileMode)
+	if errors.Is(err, os.ErrExist) {
+		return fmt.Errorf("%w: %s", ErrKeyExists, name)
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(b); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// ImportJSON stores a keystore v3 JSON key as [name]. [passphrase] must
+// decrypt [keyJSON] and is also used to encrypt the stored key.
+func (ks *Keystore) ImportJSON(name string, keyJSON []byte, passphrase string) (*ecdsa.PrivateKey, error) {
+	key, err := keystore.DecryptKey(keyJSON, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	if err := ks.Import(name, key.PrivateKey, passphrase); err != nil {
+		return nil, err
+	}
+	return key.PrivateKey, nil
+}
+
+// Load decrypts the key stored as [name].
+func (ks *Keystore) Load(name string, passphrase string) (*ecdsa.PrivateKey, error) {
+	b, err := ks.Export(name)
+	if err != nil {
+		return nil, err
+	}
+	key, err := keystore.DecryptKey(b, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return key.PrivateKey, nil
+}
+
+// Export returns the (encrypted) keystore v3 JSON of [name].
+func (ks *Keystore) Export(name string) ([]byte, error) {
+	if err := parser.CheckContents(name); err != nil {
+		return nil, fmt.Errorf("%w: invalid key name", err)
+	}
+	b, err := os.ReadFile(ks.Path(name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrKeyMissing, name)
+	}
+	return b, err
+}
+
+// List returns all stored keys (sorted by name). Addresses are read from the
+// key files, so no passphrase is required.
+func (ks *Keystore) List() ([]*Entry, error) {
+	files, err := os.ReadDir(ks.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return []*Entry{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	entries := []*Entry{}
+	for _, f := range files {
+		name := strings.TrimSuffix(f.Name(), keyExt)
+		if f.IsDir() || name == f.Name() || parser.CheckContents(name) != nil {
+			continue
+		}
+		b, err := os.ReadFile(filepath.Join(ks.dir, f.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var k struct {
+			Address string `json:"address"`
+		}
+		if err := json.Unmarshal(b, &k); err != nil || !common.IsHexAddress(k.Address) {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidKey, f.Name())
+		}
+		entries = append(entries, &Entry{Name: name, Address: common.HexToAddress(k.Address)})
+	}
+	return entries, nil
+}
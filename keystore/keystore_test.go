@@ -0,0 +1,88 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package keystore
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func newTestKeystore(t *testing.T) *Keystore {
+	ks := New(t.TempDir())
+	ks.scryptN, ks.scryptP = keystore.LightScryptN, keystore.LightScryptP
+	return ks
+}
+
+func TestKeystore(t *testing.T) {
+	t.Parallel()
+
+	ks := newTestKeystore(t)
+	if entries, err := ks.List(); err != nil || len(entries) != 0 {
+		t.Fatalf("expected empty keystore, got %v (err=%v)", entries, err)
+	}
+
+	priv, err := ks.Create("bob", "pass")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ks.Create("bob", "pass"); !errors.Is(err, ErrKeyExists) {
+		t.Fatalf("expected %v, got %v", ErrKeyExists, err)
+	}
+	if _, err := ks.Create("Bob/..", "pass"); err == nil {
+		t.Fatal("expected invalid name to fail")
+	}
+	if _, err := ks.Load("alice", "pass"); !errors.Is(err, ErrKeyMissing) {
+		t.Fatalf("expected %v, got %v", ErrKeyMissing, err)
+	}
+	if _, err := ks.Load("bob", "wrong"); !errors.Is(err, keystore.ErrDecrypt) {
+		t.Fatalf("expected %v, got %v", keystore.ErrDecrypt, err)
+	}
+	loaded, err := ks.Load("bob", "pass")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !loaded.Equal(priv) {
+		t.Fatal("loaded key does not match created key")
+	}
+	fi, err := os.Stat(ks.Path("bob"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode().Perm() != fileMode {
+		t.Fatalf("expected mode %o, got %o", fileMode, fi.Mode().Perm())
+	}
+
+	// Exported keys must be readable by go-ethereum
+	b, err := ks.Export("bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := keystore.DecryptKey(b, "pass")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key.Address != crypto.PubkeyToAddress(priv.PublicKey) {
+		t.Fatalf("unexpected address %s", key.Address)
+	}
+	if _, err := ks.ImportJSON("alice", b, "pass"); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := ks.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Name != "alice" || entries[1].Name != "bob" {
+		t.Fatalf("unexpected entries %+v", entries)
+	}
+	for _, e := range entries {
+		if e.Address != key.Address {
+			t.Fatalf("unexpected address %s for %s", e.Address, e.Name)
+		}
+	}
+}
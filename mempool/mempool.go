@@ -27,6 +27,8 @@ type Mempool struct {
 	Pending chan struct{}
 	// newTxs is an array of [Tx] that are ready to be gossiped.
 	newTxs []*chain.Transaction
+	// evicted is the number of txs removed because the mempool was full.
+	evicted uint64
 }
 
 // New creates a new [Mempool]. [maxSize] must be > 0 or else the
@@ -75,6 +77,7 @@ func (th *Mempool) Add(tx *chain.Transaction) bool {
 	// lowest paying transaction
 	if th.maxHeap.Len() > th.maxSize {
 		t, _ := th.popMin()
+		th.evicted++
 		if t.ID() == txID {
 			return false
 		}
@@ -173,6 +176,26 @@ func (th *Mempool) Has(id ids.ID) bool {
 	return th.maxHeap.Has(id)
 }
 
+// Txs returns all transactions in the mempool in no particular order.
+func (th *Mempool) Txs() []*chain.Transaction {
+	th.mu.RLock()
+	defer th.mu.RUnlock()
+
+	txs := make([]*chain.Transaction, 0, th.maxHeap.Len())
+	for _, txE := range th.maxHeap.items {
+		txs = append(txs, txE.tx)
+	}
+	return txs
+}
+
+// Evicted returns the number of txs removed because the mempool was full.
+func (th *Mempool) Evicted() uint64 {
+	th.mu.RLock()
+	defer th.mu.RUnlock()
+
+	return th.evicted
+}
+
 // GetNewTxs returns the array of [newTxs] and replaces it with a new array.
 func (th *Mempool) NewTxs(maxUnits uint64) []*chain.Transaction {
 	th.mu.Lock()
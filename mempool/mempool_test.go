@@ -54,4 +54,7 @@ func TestMempool(t *testing.T) {
 	if length := txm.Len(); length != 3 {
 		t.Fatalf("length expected 3, got %d", length)
 	}
+	if evicted := txm.Evicted(); evicted != 1 {
+		t.Fatalf("evicted expected 1, got %d", evicted)
+	}
 }
@@ -254,6 +254,7 @@ done:
 	priv, err = crypto.HexToECDSA("a1c0bd71ff64aebd666b04db0531d61479c2c031e4de38410de0609cbd6e66f0")
 	gomega.Ω(err).Should(gomega.BeNil())
 	sender = crypto.PubkeyToAddress(priv.PublicKey)
+	signer = client.NewKeySigner(priv)
 
 	instances = make([]instance, len(uris))
 	for i := range uris {
@@ -269,6 +270,7 @@ done:
 
 var (
 	priv   *ecdsa.PrivateKey
+	signer client.Signer
 	sender eth_common.Address
 
 	instances []instance
@@ -353,9 +355,8 @@ var _ = ginkgo.Describe("[Claim/SetTx]", func() {
 				ctx,
 				instances[0].cli,
 				claimTx,
-				priv,
+				signer,
 				client.WithPollTx(),
-				client.WithInfo(space),
 			)
 			cancel()
 			gomega.Ω(err).Should(gomega.BeNil())
@@ -394,9 +395,8 @@ var _ = ginkgo.Describe("[Claim/SetTx]", func() {
 				ctx,
 				cli,
 				setTx,
-				priv,
+				signer,
 				client.WithPollTx(),
-				client.WithInfo(space),
 			)
 			cancel()
 			gomega.Ω(err).Should(gomega.BeNil())
@@ -444,9 +444,8 @@ var _ = ginkgo.Describe("[Claim/SetTx]", func() {
 				ctx,
 				cli,
 				setTx,
-				priv,
+				signer,
 				client.WithPollTx(),
-				client.WithInfo(space),
 			)
 			cancel()
 			gomega.Ω(err).Should(gomega.BeNil())
@@ -492,9 +491,8 @@ var _ = ginkgo.Describe("[Claim/SetTx]", func() {
 				ctx,
 				cli,
 				deleteTx,
-				priv,
+				signer,
 				client.WithPollTx(),
-				client.WithInfo(space),
 			)
 			cancel()
 			gomega.Ω(err).Should(gomega.BeNil())
@@ -536,9 +534,8 @@ var _ = ginkgo.Describe("[Claim/SetTx]", func() {
 					Typ:   chain.Claim,
 					Space: space,
 				},
-				priv,
+				signer,
 				client.WithPollTx(),
-				client.WithInfo(space),
 			)
 			cancel()
 			gomega.Ω(err).Should(gomega.BeNil())
@@ -575,9 +572,8 @@ var _ = ginkgo.Describe("[Claim/SetTx]", func() {
 					Key:   k,
 					Value: v,
 				},
-				priv,
+				signer,
 				client.WithPollTx(),
-				client.WithInfo(space),
 			)
 			cancel()
 			gomega.Ω(err).Should(gomega.BeNil())
@@ -623,9 +619,8 @@ var _ = ginkgo.Describe("[Claim/SetTx]", func() {
 					Key:   k,
 					Value: v2,
 				},
-				priv,
+				signer,
 				client.WithPollTx(),
-				client.WithInfo(space),
 			)
 			cancel()
 			gomega.Ω(err).Should(gomega.BeNil())
@@ -669,9 +664,8 @@ var _ = ginkgo.Describe("[Claim/SetTx]", func() {
 					Space: space,
 					Key:   k,
 				},
-				priv,
+				signer,
 				client.WithPollTx(),
-				client.WithInfo(space),
 			)
 			cancel()
 			gomega.Ω(err).Should(gomega.BeNil())
@@ -5,11 +5,13 @@
 package integration_test
 
 import (
+	"bytes"
 	"context"
 	"crypto/ecdsa"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
@@ -17,10 +19,12 @@ import (
 	"math/rand"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/ava-labs/avalanchego/api/metrics"
 	"github.com/ava-labs/avalanchego/database/manager"
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/snow"
@@ -85,6 +89,7 @@ var (
 
 	// when used with embedded VMs
 	genesisBytes []byte
+	upgradeBytes = []byte(`{"upgrades":[{"timestamp":0,"enabledTxs":["subscribe"]}]}`)
 	instances    []instance
 
 	genesis *chain.Genesis
@@ -147,6 +152,7 @@ var _ = ginkgo.BeforeSuite(func() {
 			SubnetID:  subnetID,
 			ChainID:   chainID,
 			NodeID:    ids.GenerateTestNodeID(),
+			Metrics:   metrics.NewOptionalGatherer(),
 		}
 
 		toEngine := make(chan common.Message, 1)
@@ -159,7 +165,7 @@ var _ = ginkgo.BeforeSuite(func() {
 			ctx,
 			db,
 			genesisBytes,
-			nil,
+			upgradeBytes,
 			nil,
 			toEngine,
 			nil,
@@ -280,7 +286,7 @@ var _ = ginkgo.Describe("Tx Types", func() {
 
 		ginkgo.By("mine and issue ClaimTx", func() {
 			ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
-			_, _, err := client.SignIssueRawTx(ctx, instances[0].cli, claimTx, priv)
+			_, _, err := client.SignIssueRawTx(ctx, instances[0].cli, claimTx, client.NewKeySigner(priv))
 			cancel()
 			gomega.Ω(err).Should(gomega.BeNil())
 		})
@@ -542,7 +548,7 @@ var _ = ginkgo.Describe("Tx Types", func() {
 
 		ginkgo.By("mine and issue ClaimTx", func() {
 			ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
-			_, _, err := client.SignIssueRawTx(ctx, instances[0].cli, claimTx, priv)
+			_, _, err := client.SignIssueRawTx(ctx, instances[0].cli, claimTx, client.NewKeySigner(priv))
 			cancel()
 			gomega.Ω(err).Should(gomega.BeNil())
 		})
@@ -599,6 +605,7 @@ var _ = ginkgo.Describe("Tx Types", func() {
 			var path string
 			var originalFile *os.File
 			var err error
+			manifest := filepath.Join(ginkgo.GinkgoT().TempDir(), "manifest.json")
 			ginkgo.By("upload file", func() {
 				originalFile, err = os.Open(file)
 				gomega.Ω(err).Should(gomega.BeNil())
@@ -610,14 +617,41 @@ var _ = ginkgo.Describe("Tx Types", func() {
 					close(d)
 				}()
 				path, err = tree.Upload(
-					context.Background(), instances[0].cli, priv,
+					context.Background(), instances[0].cli, client.NewKeySigner(priv),
 					space, originalFile, int(genesis.MaxValueSize),
+					tree.WithManifest(manifest), tree.WithFanout(4),
 				)
 				gomega.Ω(err).Should(gomega.BeNil())
 				close(c)
 				<-d
 			})
 
+			ginkgo.By("resume completed upload from manifest", func() {
+				m, err := tree.LoadManifest(manifest)
+				gomega.Ω(err).Should(gomega.BeNil())
+				gomega.Ω(m.Path).Should(gomega.Equal(path))
+				for _, c := range m.Chunks {
+					gomega.Ω(c.Confirmed).Should(gomega.BeTrue())
+				}
+
+				_, err = originalFile.Seek(0, io.SeekStart)
+				gomega.Ω(err).Should(gomega.BeNil())
+				issued := 0
+				resumed, err := tree.Upload(
+					context.Background(), instances[0].cli, client.NewKeySigner(priv),
+					space, originalFile, int(genesis.MaxValueSize),
+					tree.WithManifest(manifest), tree.WithFanout(4),
+					tree.WithProgress(func(e *tree.Event) {
+						if e.Op != tree.EventSkip {
+							issued++
+						}
+					}),
+				)
+				gomega.Ω(err).Should(gomega.BeNil())
+				gomega.Ω(resumed).Should(gomega.Equal(path))
+				gomega.Ω(issued).Should(gomega.Equal(0))
+			})
+
 			var newFile *os.File
 			ginkgo.By("download file", func() {
 				newFile, err = ioutil.TempFile("", "computer")
@@ -625,6 +659,104 @@ var _ = ginkgo.Describe("Tx Types", func() {
 
 				err = tree.Download(context.Background(), instances[0].cli, path, newFile)
 				gomega.Ω(err).Should(gomega.BeNil())
+
+				// Files with more than [WithFanout] chunks have index nodes
+				_, rb, _, err := instances[0].cli.Resolve(context.Background(), path)
+				gomega.Ω(err).Should(gomega.BeNil())
+				var r tree.Node
+				gomega.Ω(json.Unmarshal(rb, &r)).Should(gomega.BeNil())
+				gomega.Ω(r.Version).Should(gomega.Equal(uint64(tree.NodeVersion)))
+				info, err := originalFile.Stat()
+				gomega.Ω(err).Should(gomega.BeNil())
+				gomega.Ω(r.Size).Should(gomega.Equal(uint64(info.Size())))
+				if info.Size() > 4*int64(genesis.MaxValueSize) {
+					gomega.Ω(r.Height).Should(gomega.BeNumerically(">", 0))
+				}
+
+				// Metadata is populated from the uploaded file
+				stat, err := tree.Stat(context.Background(), instances[0].cli, path)
+				gomega.Ω(err).Should(gomega.BeNil())
+				gomega.Ω(stat.Meta).ShouldNot(gomega.BeNil())
+				gomega.Ω(stat.Meta.Name).Should(gomega.Equal(filepath.Base(file)))
+				gomega.Ω(stat.Meta.ContentType).ShouldNot(gomega.BeEmpty())
+				gomega.Ω(stat.Meta.Modified).Should(gomega.Equal(info.ModTime().Unix()))
+				contents, err := os.ReadFile(file)
+				gomega.Ω(err).Should(gomega.BeNil())
+				sum := sha256.Sum256(contents)
+				gomega.Ω(stat.Meta.SHA256).Should(gomega.Equal(hex.EncodeToString(sum[:])))
+			})
+
+			ginkgo.By("download file from multiple nodes", func() {
+				// Other instances have not accepted any blocks so every chunk
+				// they are asked for must be retried on instances[0]
+				lagging, err := ioutil.TempFile("", "computer")
+				gomega.Ω(err).Should(gomega.BeNil())
+				defer lagging.Close()
+				err = tree.Download(
+					context.Background(), instances[0].cli, path, lagging,
+					tree.WithWorkers(8), tree.WithRetries(1), tree.WithClients(instances[1].cli),
+				)
+				gomega.Ω(err).Should(gomega.BeNil())
+
+				_, err = newFile.Seek(0, io.SeekStart)
+				gomega.Ω(err).Should(gomega.BeNil())
+				expected, err := io.ReadAll(newFile)
+				gomega.Ω(err).Should(gomega.BeNil())
+				_, err = lagging.Seek(0, io.SeekStart)
+				gomega.Ω(err).Should(gomega.BeNil())
+				actual, err := io.ReadAll(lagging)
+				gomega.Ω(err).Should(gomega.BeNil())
+				gomega.Ω(actual).Should(gomega.Equal(expected))
+			})
+
+			ginkgo.By("download version 0 file", func() {
+				// Version 0 roots list every chunk
+				_, err = originalFile.Seek(0, io.SeekStart)
+				gomega.Ω(err).Should(gomega.BeNil())
+				chunk := make([]byte, genesis.MaxValueSize)
+				legacy := &struct {
+					Contents []byte   `json:"contents"`
+					Children []string `json:"children"`
+				}{}
+				for {
+					n, err := io.ReadFull(originalFile, chunk)
+					if n > 0 {
+						legacy.Children = append(legacy.Children, strings.ToLower(ecommon.Bytes2Hex(crypto.Keccak256(chunk[:n]))))
+					}
+					if err != nil {
+						break
+					}
+				}
+				if len(legacy.Children) < 2 {
+					// Small files are stored in the contents of the root
+					return
+				}
+				rb, err := json.Marshal(legacy)
+				gomega.Ω(err).Should(gomega.BeNil())
+				rk := strings.ToLower(ecommon.Bytes2Hex(crypto.Keccak256(rb)))
+				createIssueRawTx(instances[0], &chain.SetTx{
+					BaseTx: &chain.BaseTx{},
+					Space:  space,
+					Key:    rk,
+					Value:  rb,
+				}, priv)
+				expectBlkAccept(instances[0])
+
+				downloaded, err := ioutil.TempFile("", "legacy")
+				gomega.Ω(err).Should(gomega.BeNil())
+				defer downloaded.Close()
+				err = tree.Download(context.Background(), instances[0].cli, space+"/"+rk, downloaded)
+				gomega.Ω(err).Should(gomega.BeNil())
+
+				_, err = originalFile.Seek(0, io.SeekStart)
+				gomega.Ω(err).Should(gomega.BeNil())
+				expected, err := io.ReadAll(originalFile)
+				gomega.Ω(err).Should(gomega.BeNil())
+				_, err = downloaded.Seek(0, io.SeekStart)
+				gomega.Ω(err).Should(gomega.BeNil())
+				actual, err := io.ReadAll(downloaded)
+				gomega.Ω(err).Should(gomega.BeNil())
+				gomega.Ω(actual).Should(gomega.Equal(expected))
 			})
 
 			ginkgo.By("compare file contents", func() {
@@ -655,7 +787,7 @@ var _ = ginkgo.Describe("Tx Types", func() {
 					asyncBlockPush(instances[0], c)
 					close(d)
 				}()
-				err = tree.Delete(context.Background(), instances[0].cli, path, priv)
+				err = tree.Delete(context.Background(), instances[0].cli, path, client.NewKeySigner(priv))
 				gomega.Ω(err).Should(gomega.BeNil())
 				close(c)
 				<-d
@@ -668,6 +800,669 @@ var _ = ginkgo.Describe("Tx Types", func() {
 				dummyFile.Close()
 			})
 		}
+
+		ginkgo.By("reject chunk that does not match its key", func() {
+			// Chunks are fetched by key, so a value stored at the wrong key
+			// must fail the download instead of being written
+			good, expected := []byte("good"), []byte("expected")
+			gk := strings.ToLower(ecommon.Bytes2Hex(crypto.Keccak256(good)))
+			bk := strings.ToLower(ecommon.Bytes2Hex(crypto.Keccak256(expected)))
+			rb, err := json.Marshal(&tree.Node{
+				Version:  tree.NodeVersion,
+				Size:     uint64(len(good) + len(expected)),
+				Children: []string{gk, bk},
+			})
+			gomega.Ω(err).Should(gomega.BeNil())
+			rk := strings.ToLower(ecommon.Bytes2Hex(crypto.Keccak256(rb)))
+			for k, v := range map[string][]byte{gk: good, bk: []byte("tampered"), rk: rb} {
+				createIssueRawTx(instances[0], &chain.SetTx{
+					BaseTx: &chain.BaseTx{},
+					Space:  space,
+					Key:    k,
+					Value:  v,
+				}, priv)
+				expectBlkAccept(instances[0])
+			}
+
+			err = tree.Download(context.Background(), instances[0].cli, space+"/"+rk, io.Discard, tree.WithRetries(1))
+			gomega.Ω(errors.Is(err, tree.ErrIntegrity)).Should(gomega.BeTrue())
+		})
+
+		ginkgo.By("reject file with wrong sha256", func() {
+			other := sha256.Sum256([]byte("other"))
+			rb, err := json.Marshal(&tree.Node{
+				Version:  tree.NodeVersion,
+				Size:     8,
+				Meta:     &tree.FileMeta{SHA256: hex.EncodeToString(other[:])},
+				Contents: []byte("contents"),
+			})
+			gomega.Ω(err).Should(gomega.BeNil())
+			rk := strings.ToLower(ecommon.Bytes2Hex(crypto.Keccak256(rb)))
+			createIssueRawTx(instances[0], &chain.SetTx{
+				BaseTx: &chain.BaseTx{},
+				Space:  space,
+				Key:    rk,
+				Value:  rb,
+			}, priv)
+			expectBlkAccept(instances[0])
+
+			err = tree.Download(context.Background(), instances[0].cli, space+"/"+rk, io.Discard)
+			gomega.Ω(errors.Is(err, tree.ErrIntegrity)).Should(gomega.BeTrue())
+		})
+
+		ginkgo.By("upload and download encrypted files", func() {
+			withKey := func(k *ecdsa.PrivateKey) tree.OpOption {
+				return tree.WithDecryptionKey(func() (*ecdsa.PrivateKey, error) { return k, nil })
+			}
+			for _, size := range []int{units.KiB, 3 * units.MiB} {
+				contents := []byte(RandStringRunes(size))
+				p := filepath.Join(ginkgo.GinkgoT().TempDir(), "secret.txt")
+				gomega.Ω(os.WriteFile(p, contents, 0o600)).Should(gomega.BeNil())
+				f, err := os.Open(p)
+				gomega.Ω(err).Should(gomega.BeNil())
+
+				c := make(chan struct{})
+				d := make(chan struct{})
+				go func() {
+					asyncBlockPush(instances[0], c)
+					close(d)
+				}()
+				path, err := tree.Upload(
+					context.Background(), instances[0].cli, client.NewKeySigner(priv),
+					space, f, int(genesis.MaxValueSize),
+					tree.WithFanout(4), tree.WithEncryption(&priv2.PublicKey),
+				)
+				close(c)
+				<-d
+				f.Close()
+				gomega.Ω(err).Should(gomega.BeNil())
+
+				// Metadata is only visible to recipients
+				stat, err := tree.Stat(context.Background(), instances[0].cli, path)
+				gomega.Ω(err).Should(gomega.BeNil())
+				gomega.Ω(stat.Version).Should(gomega.Equal(uint64(tree.EncodedNodeVersion)))
+				gomega.Ω(stat.Meta).Should(gomega.BeNil())
+				gomega.Ω(stat.Encryption.Recipients).Should(gomega.HaveLen(1))
+				gomega.Ω(stat.Encryption.Recipients[0].Address).Should(gomega.Equal(sender2))
+				stat, err = tree.Stat(context.Background(), instances[0].cli, path, withKey(priv2))
+				gomega.Ω(err).Should(gomega.BeNil())
+				gomega.Ω(stat.Meta.Name).Should(gomega.Equal("secret.txt"))
+				gomega.Ω(stat.Meta.ContentType).Should(gomega.HavePrefix("text/plain"))
+
+				err = tree.Download(context.Background(), instances[0].cli, path, io.Discard)
+				gomega.Ω(err).Should(gomega.MatchError(tree.ErrEncrypted))
+				err = tree.Download(context.Background(), instances[0].cli, path, io.Discard, withKey(priv))
+				gomega.Ω(errors.Is(err, client.ErrNotRecipient)).Should(gomega.BeTrue())
+
+				var downloaded bytes.Buffer
+				err = tree.Download(context.Background(), instances[0].cli, path, &downloaded, withKey(priv2))
+				gomega.Ω(err).Should(gomega.BeNil())
+				gomega.Ω(downloaded.Bytes()).Should(gomega.Equal(contents))
+			}
+		})
+
+		ginkgo.By("upload and download compressed files", func() {
+			for _, encrypt := range []bool{false, true} {
+				for _, size := range []int{units.KiB, 3 * units.MiB} {
+					contents := []byte(strings.Repeat(RandStringRunes(64), size/64))
+					opts := []tree.OpOption{tree.WithCompression(tree.CompressionGzip)}
+					if encrypt {
+						opts = append(opts, tree.WithEncryption(&priv.PublicKey))
+					}
+					var totalCost, totalSaved uint64
+					opts = append(opts, tree.WithProgress(func(e *tree.Event) {
+						if e.Op == tree.EventUpload {
+							totalCost, totalSaved = e.TotalCost, e.TotalSaved
+						}
+					}))
+
+					c := make(chan struct{})
+					d := make(chan struct{})
+					go func() {
+						asyncBlockPush(instances[0], c)
+						close(d)
+					}()
+					path, err := tree.Upload(
+						context.Background(), instances[0].cli, client.NewKeySigner(priv),
+						space, bytes.NewReader(contents), int(genesis.MaxValueSize), opts...,
+					)
+					close(c)
+					<-d
+					gomega.Ω(err).Should(gomega.BeNil())
+					gomega.Ω(totalCost).Should(gomega.BeNumerically(">", 0))
+					gomega.Ω(totalSaved).Should(gomega.BeNumerically(">", 0))
+
+					stat, err := tree.Stat(context.Background(), instances[0].cli, path)
+					gomega.Ω(err).Should(gomega.BeNil())
+					gomega.Ω(stat.Version).Should(gomega.Equal(uint64(tree.EncodedNodeVersion)))
+					gomega.Ω(stat.Compression).Should(gomega.Equal(tree.CompressionGzip))
+					gomega.Ω(stat.Size).Should(gomega.Equal(uint64(size)))
+
+					var downloaded bytes.Buffer
+					err = tree.Download(
+						context.Background(), instances[0].cli, path, &downloaded,
+						tree.WithDecryptionKey(func() (*ecdsa.PrivateKey, error) { return priv, nil }),
+					)
+					gomega.Ω(err).Should(gomega.BeNil())
+					gomega.Ω(downloaded.Bytes()).Should(gomega.Equal(contents))
+				}
+			}
+
+			_, err := tree.Upload(
+				context.Background(), instances[0].cli, client.NewKeySigner(priv),
+				space, strings.NewReader("hello"), int(genesis.MaxValueSize), tree.WithCompression("lz4"),
+			)
+			gomega.Ω(errors.Is(err, tree.ErrUnsupportedCompression)).Should(gomega.BeTrue())
+		})
+
+		ginkgo.By("upload new version with content-defined chunks", func() {
+			v1 := make([]byte, 3*units.MiB)
+			_, err := rand.Read(v1) //nolint:gosec
+			gomega.Ω(err).Should(gomega.BeNil())
+			v2 := append(append(append([]byte{}, v1[:units.MiB]...), []byte("nightly")...), v1[units.MiB:]...)
+
+			var paths []string
+			uploaded := make([]int, 2)
+			for i, contents := range [][]byte{v1, v2} {
+				i := i
+				c := make(chan struct{})
+				d := make(chan struct{})
+				go func() {
+					asyncBlockPush(instances[0], c)
+					close(d)
+				}()
+				path, err := tree.Upload(
+					context.Background(), instances[0].cli, client.NewKeySigner(priv),
+					space, bytes.NewReader(contents), int(genesis.MaxValueSize),
+					tree.WithContentDefinedChunking(16*units.KiB),
+					tree.WithProgress(func(e *tree.Event) {
+						if e.Op == tree.EventUpload && !e.Root && !e.Index {
+							uploaded[i]++
+						}
+					}),
+				)
+				close(c)
+				<-d
+				gomega.Ω(err).Should(gomega.BeNil())
+				paths = append(paths, path)
+
+				var downloaded bytes.Buffer
+				err = tree.Download(context.Background(), instances[0].cli, path, &downloaded)
+				gomega.Ω(err).Should(gomega.BeNil())
+				gomega.Ω(downloaded.Bytes()).Should(gomega.Equal(contents))
+			}
+			gomega.Ω(paths[0]).ShouldNot(gomega.Equal(paths[1]))
+			gomega.Ω(uploaded[0]).Should(gomega.BeNumerically(">", 50))
+			gomega.Ω(uploaded[1]).Should(gomega.BeNumerically("<=", 3))
+
+			// Chunks shared with the new version are kept
+			c := make(chan struct{})
+			d := make(chan struct{})
+			go func() {
+				asyncBlockPush(instances[0], c)
+				close(d)
+			}()
+			err = tree.Delete(context.Background(), instances[0].cli, paths[0], client.NewKeySigner(priv))
+			close(c)
+			<-d
+			gomega.Ω(err).Should(gomega.BeNil())
+			var downloaded bytes.Buffer
+			err = tree.Download(context.Background(), instances[0].cli, paths[1], &downloaded)
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(downloaded.Bytes()).Should(gomega.Equal(v2))
+		})
+
+		ginkgo.By("garbage collect orphaned chunks", func() {
+			upload := func(contents []byte) (string, *tree.Node) {
+				c := make(chan struct{})
+				d := make(chan struct{})
+				go func() {
+					asyncBlockPush(instances[0], c)
+					close(d)
+				}()
+				// Multiple levels of index nodes
+				path, err := tree.Upload(
+					context.Background(), instances[0].cli, client.NewKeySigner(priv),
+					space, bytes.NewReader(contents), int(genesis.MaxValueSize), tree.WithFanout(2),
+				)
+				close(c)
+				<-d
+				gomega.Ω(err).Should(gomega.BeNil())
+				r, err := tree.Stat(context.Background(), instances[0].cli, path)
+				gomega.Ω(err).Should(gomega.BeNil())
+				gomega.Ω(r.Height).Should(gomega.BeNumerically(">", 1))
+				return path, r
+			}
+			// Keys of the index nodes and chunks under a root
+			descendants := func(n *tree.Node) []string {
+				keys := []string{}
+				nodes := []*tree.Node{n}
+				for len(nodes) > 0 {
+					n := nodes[0]
+					nodes = nodes[1:]
+					keys = append(keys, n.Children...)
+					if n.Height == 0 {
+						continue
+					}
+					for _, k := range n.Children {
+						_, b, _, err := instances[0].cli.Resolve(context.Background(), space+"/"+k)
+						gomega.Ω(err).Should(gomega.BeNil())
+						child := new(tree.Node)
+						gomega.Ω(json.Unmarshal(b, child)).Should(gomega.BeNil())
+						nodes = append(nodes, child)
+					}
+				}
+				return keys
+			}
+			live := []byte(RandStringRunes(units.MiB))
+			livePath, liveRoot := upload(live)
+			liveKeys := descendants(liveRoot)
+			deletedPath, deletedRoot := upload([]byte(RandStringRunes(units.MiB)))
+			deletedKeys := descendants(deletedRoot)
+
+			// Deleting only the root orphans its chunks
+			createIssueRawTx(instances[0], &chain.DeleteTx{
+				BaseTx: &chain.BaseTx{},
+				Space:  space,
+				Key:    strings.Split(deletedPath, "/")[1],
+			}, priv)
+			expectBlkAccept(instances[0])
+
+			r, err := tree.GC(
+				context.Background(), instances[0].cli, nil, space,
+				tree.WithDryRun(), tree.WithMinAge(0),
+			)
+			gomega.Ω(err).Should(gomega.BeNil())
+			// Index nodes of the deleted root are collected with its chunks
+			gomega.Ω(r.Orphans).Should(gomega.ContainElements(deletedKeys))
+			for _, k := range liveKeys {
+				gomega.Ω(r.Orphans).ShouldNot(gomega.ContainElement(k))
+			}
+			gomega.Ω(r.NewUnits).Should(gomega.BeNumerically("<", r.Units))
+			gomega.Ω(r.NewExpiry).Should(gomega.BeNumerically(">", r.Expiry))
+			gomega.Ω(r.TotalCost).Should(gomega.BeZero())
+
+			// Recently updated chunks are not deleted by default
+			r, err = tree.GC(context.Background(), instances[0].cli, nil, space, tree.WithDryRun())
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(r.Orphans).Should(gomega.BeEmpty())
+
+			c := make(chan struct{})
+			d := make(chan struct{})
+			go func() {
+				asyncBlockPush(instances[0], c)
+				close(d)
+			}()
+			r, err = tree.GC(
+				context.Background(), instances[0].cli, client.NewKeySigner(priv), space,
+				tree.WithMinAge(0),
+			)
+			close(c)
+			<-d
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(r.TotalCost).Should(gomega.BeNumerically(">", 0))
+			exists, err := instances[0].cli.HasKeys(context.Background(), space, deletedKeys)
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(exists).ShouldNot(gomega.ContainElement(true))
+
+			var downloaded bytes.Buffer
+			err = tree.Download(context.Background(), instances[0].cli, livePath, &downloaded)
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(downloaded.Bytes()).Should(gomega.Equal(live))
+
+			r, err = tree.GC(
+				context.Background(), instances[0].cli, nil, space,
+				tree.WithDryRun(), tree.WithMinAge(0),
+			)
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(r.Orphans).Should(gomega.BeEmpty())
+			gomega.Ω(r.Roots).Should(gomega.BeNumerically(">", 0))
+		})
+
+		ginkgo.By("set and resolve sealed value", func() {
+			sealed, err := client.Seal([]byte("secret config"), &priv.PublicKey, &priv2.PublicKey)
+			gomega.Ω(err).Should(gomega.BeNil())
+			createIssueRawTx(instances[0], &chain.SetTx{
+				BaseTx: &chain.BaseTx{},
+				Space:  space,
+				Key:    "config",
+				Value:  sealed,
+			}, priv)
+			expectBlkAccept(instances[0])
+
+			_, v, _, err := instances[0].cli.Resolve(context.Background(), space+"/config")
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(client.IsSealed(v)).Should(gomega.BeTrue())
+			gomega.Ω(bytes.Contains(v, []byte("secret config"))).Should(gomega.BeFalse())
+			for _, k := range []*ecdsa.PrivateKey{priv, priv2} {
+				plain, err := client.Open(v, k)
+				gomega.Ω(err).Should(gomega.BeNil())
+				gomega.Ω(plain).Should(gomega.Equal([]byte("secret config")))
+			}
+			other, err := crypto.GenerateKey()
+			gomega.Ω(err).Should(gomega.BeNil())
+			_, err = client.Open(v, other)
+			gomega.Ω(errors.Is(err, client.ErrNotRecipient)).Should(gomega.BeTrue())
+			_, err = client.Open([]byte("plaintext"), priv)
+			gomega.Ω(err).Should(gomega.MatchError(client.ErrNotSealed))
+		})
+
+		ginkgo.By("upload and download directory", func() {
+			src := ginkgo.GinkgoT().TempDir()
+			big := []byte(RandStringRunes(3 * units.MiB))
+			contents := map[string][]byte{
+				"index.html":       []byte("<html></html>"),
+				"empty":            {},
+				"assets/big.bin":   big,
+				"assets/copy.bin":  big,
+				"assets/css/a.css": []byte("body {}"),
+			}
+			for name, b := range contents {
+				p := filepath.Join(src, name)
+				gomega.Ω(os.MkdirAll(filepath.Dir(p), 0o755)).Should(gomega.BeNil())
+				gomega.Ω(os.WriteFile(p, b, 0o640)).Should(gomega.BeNil())
+			}
+			mtime := time.Unix(1_600_000_000, 0)
+			gomega.Ω(os.Chtimes(filepath.Join(src, "index.html"), mtime, mtime)).Should(gomega.BeNil())
+
+			c := make(chan struct{})
+			d := make(chan struct{})
+			go func() {
+				asyncBlockPush(instances[0], c)
+				close(d)
+			}()
+			uploads := map[string]int{}
+			path, err := tree.UploadDir(
+				context.Background(), instances[0].cli, client.NewKeySigner(priv),
+				space, src, int(genesis.MaxValueSize),
+				tree.WithProgress(func(e *tree.Event) {
+					if e.Op == tree.EventUpload {
+						uploads[e.Key]++
+					}
+				}),
+			)
+			gomega.Ω(err).Should(gomega.BeNil())
+			close(c)
+			<-d
+			// Chunks shared by files are only issued once
+			for _, n := range uploads {
+				gomega.Ω(n).Should(gomega.Equal(1))
+			}
+
+			dst := filepath.Join(ginkgo.GinkgoT().TempDir(), "site")
+			err = tree.DownloadDir(context.Background(), instances[0].cli, path, dst)
+			gomega.Ω(err).Should(gomega.BeNil())
+			for name, b := range contents {
+				actual, err := os.ReadFile(filepath.Join(dst, name))
+				gomega.Ω(err).Should(gomega.BeNil())
+				gomega.Ω(actual).Should(gomega.Equal(b))
+			}
+			info, err := os.Stat(filepath.Join(dst, "index.html"))
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(info.Mode().Perm()).Should(gomega.Equal(os.FileMode(0o640)))
+			gomega.Ω(info.ModTime().Unix()).Should(gomega.Equal(mtime.Unix()))
+
+			// The destination must not exist
+			err = tree.DownloadDir(context.Background(), instances[0].cli, path, dst)
+			gomega.Ω(err).Should(gomega.MatchError(os.ErrExist))
+
+			// Entries of a directory can only be deleted with the directory
+			_, b, _, err := instances[0].cli.Resolve(context.Background(), path)
+			gomega.Ω(err).Should(gomega.BeNil())
+			root := new(tree.Dir)
+			gomega.Ω(json.Unmarshal(b, root)).Should(gomega.BeNil())
+			var indexKey string
+			for _, e := range root.Entries {
+				if e.Name == "index.html" {
+					indexKey = e.Key
+				}
+			}
+			err = tree.Delete(context.Background(), instances[0].cli, space+"/"+indexKey, client.NewKeySigner(priv))
+			gomega.Ω(errors.Is(err, tree.ErrShared)).Should(gomega.BeTrue())
+		})
+
+		ginkgo.By("delete file sharing chunks with another file", func() {
+			// Both chunks of [first] are shared with [second]
+			first := []byte(RandStringRunes(2 * int(genesis.MaxValueSize)))
+			second := append(append([]byte{}, first...), "tail"...)
+
+			c := make(chan struct{})
+			d := make(chan struct{})
+			go func() {
+				asyncBlockPush(instances[0], c)
+				close(d)
+			}()
+			paths := []string{}
+			for _, b := range [][]byte{first, second} {
+				path, err := tree.Upload(
+					context.Background(), instances[0].cli, client.NewKeySigner(priv),
+					space, bytes.NewReader(b), int(genesis.MaxValueSize),
+				)
+				gomega.Ω(err).Should(gomega.BeNil())
+				paths = append(paths, path)
+			}
+			err := tree.Delete(context.Background(), instances[0].cli, paths[0], client.NewKeySigner(priv))
+			close(c)
+			<-d
+			gomega.Ω(err).Should(gomega.BeNil())
+
+			var downloaded bytes.Buffer
+			err = tree.Download(context.Background(), instances[0].cli, paths[1], &downloaded)
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(downloaded.Bytes()).Should(gomega.Equal(second))
+		})
+	})
+
+	ginkgo.It("simulate SetTx", func() {
+		space := strings.Repeat("b", parser.MaxIdentifierSize)
+		info, _, err := instances[0].cli.Info(context.Background(), space)
+		gomega.Ω(err).Should(gomega.BeNil())
+		td, _, _, err := instances[0].cli.SuggestedFee(context.Background(), &chain.Input{
+			Typ:   chain.Set,
+			Space: space,
+			Key:   "simulated",
+			Value: bytes.Repeat([]byte{1}, int(genesis.ValueUnitSize*genesis.ValueExpiryDiscount)*2),
+		})
+		gomega.Ω(err).Should(gomega.BeNil())
+
+		ginkgo.By("simulate as owner", func() {
+			sim, err := instances[0].cli.SimulateTx(context.Background(), td, nil, sender)
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(sim.Error).Should(gomega.BeEmpty())
+			gomega.Ω(sim.LoadUnits).Should(gomega.BeNumerically(">", 0))
+			gomega.Ω(sim.Info.Units).Should(gomega.BeNumerically(">", info.Units))
+			gomega.Ω(sim.Info.Expiry).Should(gomega.BeNumerically("<", info.Expiry))
+
+			// Nothing should be persisted
+			_, values, err := instances[0].cli.Info(context.Background(), space)
+			gomega.Ω(err).Should(gomega.BeNil())
+			for _, v := range values {
+				gomega.Ω(v.Key).ShouldNot(gomega.Equal("simulated"))
+			}
+		})
+
+		ginkgo.By("simulate as non-owner", func() {
+			sim, err := instances[0].cli.SimulateTx(context.Background(), td, nil, ecommon.Address{1})
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(sim.Error).ShouldNot(gomega.BeEmpty())
+			// State before the tx is reported
+			gomega.Ω(sim.Balance).Should(gomega.BeZero())
+			gomega.Ω(sim.Info).Should(gomega.Equal(info))
+		})
+	})
+
+	ginkgo.It("project space", func() {
+		space := strings.Repeat("b", parser.MaxIdentifierSize)
+		info, _, err := instances[0].cli.Info(context.Background(), space)
+		gomega.Ω(err).Should(gomega.BeNil())
+
+		p, err := instances[0].cli.ProjectSpace(context.Background(), space, nil, 0)
+		gomega.Ω(err).Should(gomega.BeNil())
+		gomega.Ω(p.Expiry).Should(gomega.Equal(info.Expiry))
+		gomega.Ω(p.LifelineUnits).Should(gomega.BeZero())
+
+		target := info.Expiry + 365*24*60*60
+		p, err = instances[0].cli.ProjectSpace(context.Background(), space, []*chain.ProjectedOp{
+			{Typ: chain.Set, Key: "projected", Size: genesis.MaxValueSize},
+		}, target)
+		gomega.Ω(err).Should(gomega.BeNil())
+		gomega.Ω(p.Units).Should(gomega.BeNumerically(">", info.Units))
+		gomega.Ω(p.LifelineUnits).Should(gomega.BeNumerically(">", 0))
+	})
+
+	ginkgo.It("estimate fees", func() {
+		history, err := instances[0].cli.FeeHistory(context.Background(), 5)
+		gomega.Ω(err).Should(gomega.BeNil())
+		gomega.Ω(history).Should(gomega.HaveLen(5))
+		for i := 1; i < len(history); i++ {
+			gomega.Ω(history[i].Height).Should(gomega.Equal(history[i-1].Height - 1))
+		}
+
+		estimates, err := instances[0].cli.EstimateFee(context.Background(), &chain.Input{
+			Typ:   chain.Claim,
+			Space: "feeestimate",
+		})
+		gomega.Ω(err).Should(gomega.BeNil())
+		gomega.Ω(estimates).Should(gomega.HaveLen(3))
+		for i := 1; i < len(estimates); i++ {
+			gomega.Ω(estimates[i].Blocks).Should(gomega.BeNumerically(">", estimates[i-1].Blocks))
+			gomega.Ω(estimates[i].Price).Should(gomega.BeNumerically("<=", estimates[i-1].Price))
+		}
+
+		ginkgo.By("include tx paying the next block estimate", func() {
+			utx := &chain.ClaimTx{
+				BaseTx: &chain.BaseTx{},
+				Space:  "feeestimate",
+			}
+			utx.SetMagic(genesis.Magic)
+			la, err := instances[0].cli.Accepted(context.Background())
+			gomega.Ω(err).Should(gomega.BeNil())
+			utx.SetBlockID(la)
+			utx.SetPrice(estimates[0].Price)
+
+			dh, err := chain.DigestHash(utx)
+			gomega.Ω(err).Should(gomega.BeNil())
+			sig, err := chain.Sign(dh, priv)
+			gomega.Ω(err).Should(gomega.BeNil())
+			tx := chain.NewTx(utx, sig)
+			gomega.Ω(tx.Init(genesis)).Should(gomega.BeNil())
+			_, err = instances[0].cli.IssueRawTx(context.Background(), tx.Bytes())
+			gomega.Ω(err).Should(gomega.BeNil())
+			expectBlkAccept(instances[0])
+		})
+	})
+
+	ginkgo.It("subscribe to renewals", func() {
+		space := "subscriber"
+		ginkgo.By("claim space", func() {
+			createIssueRawTx(instances[0], &chain.ClaimTx{BaseTx: &chain.BaseTx{}, Space: space}, priv)
+			expectBlkAccept(instances[0])
+		})
+
+		ginkgo.By("subscribe", func() {
+			createIssueRawTx(instances[0], &chain.SubscribeTx{
+				BaseTx: &chain.BaseTx{},
+				Space:  space,
+				Units:  1,
+				Budget: 100000,
+			}, priv)
+			expectBlkAccept(instances[0])
+
+			sub, exists, err := instances[0].cli.Subscription(context.Background(), space)
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(exists).Should(gomega.BeTrue())
+			gomega.Ω(sub).Should(gomega.Equal(&chain.Subscription{Owner: sender, Units: 1, Budget: 100000}))
+		})
+
+		ginkgo.By("cancel and refund budget", func() {
+			before, err := instances[0].cli.Balance(context.Background(), sender)
+			gomega.Ω(err).Should(gomega.BeNil())
+			createIssueRawTx(instances[0], &chain.SubscribeTx{BaseTx: &chain.BaseTx{}, Space: space}, priv)
+			expectBlkAccept(instances[0])
+
+			_, exists, err := instances[0].cli.Subscription(context.Background(), space)
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(exists).Should(gomega.BeFalse())
+			after, err := instances[0].cli.Balance(context.Background(), sender)
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(after).Should(gomega.BeNumerically(">", before))
+		})
+	})
+
+	ginkgo.It("issue with remote signer", func() {
+		srv := httptest.NewServer(client.NewSignerHandler(client.NewKeySigner(priv), client.BearerToken("secret")))
+		defer srv.Close()
+
+		ginkgo.By("reject missing or wrong token", func() {
+			for _, token := range []string{"", "wrong"} {
+				remote := client.NewRemoteSigner(srv.URL, sender, token, requestTimeout)
+				_, _, err := client.SignIssueRawTx(context.Background(), instances[0].cli, &chain.ClaimTx{
+					BaseTx: &chain.BaseTx{},
+					Space:  "remotesigner",
+				}, remote)
+				gomega.Ω(errors.Is(err, client.ErrRemoteSigner)).Should(gomega.BeTrue())
+				gomega.Ω(err.Error()).Should(gomega.ContainSubstring(client.ErrUnauthorized.Error()))
+			}
+		})
+
+		ginkgo.By("reject unknown address", func() {
+			remote := client.NewRemoteSigner(srv.URL, sender2, "secret", requestTimeout)
+			_, _, err := client.SignIssueRawTx(context.Background(), instances[0].cli, &chain.ClaimTx{
+				BaseTx: &chain.BaseTx{},
+				Space:  "remotesigner",
+			}, remote)
+			gomega.Ω(errors.Is(err, client.ErrRemoteSigner)).Should(gomega.BeTrue())
+		})
+
+		ginkgo.By("reject fee above maximum", func() {
+			remote := client.NewRemoteSigner(srv.URL, sender, "secret", requestTimeout)
+			_, _, err := client.SignIssueRawTx(context.Background(), instances[0].cli, &chain.ClaimTx{
+				BaseTx: &chain.BaseTx{},
+				Space:  "remotesigner",
+			}, remote, client.WithMaxFee(1))
+			gomega.Ω(errors.Is(err, client.ErrFeeTooHigh)).Should(gomega.BeTrue())
+		})
+
+		ginkgo.By("claim space", func() {
+			remote := client.NewRemoteSigner(srv.URL, sender, "secret", requestTimeout)
+			_, _, err := client.SignIssueRawTx(context.Background(), instances[0].cli, &chain.ClaimTx{
+				BaseTx: &chain.BaseTx{},
+				Space:  "remotesigner",
+			}, remote)
+			gomega.Ω(err).Should(gomega.BeNil())
+			expectBlkAccept(instances[0])
+
+			info, _, err := instances[0].cli.Info(context.Background(), "remotesigner")
+			gomega.Ω(err).Should(gomega.BeNil())
+			gomega.Ω(info.Owner).Should(gomega.Equal(sender))
+		})
+	})
+
+	ginkgo.It("build, sign, and send separately", func() {
+		td, _, validUntil, err := instances[0].cli.SuggestedFee(context.Background(), &chain.Input{
+			Typ:   chain.Claim,
+			Space: "offline",
+		})
+		gomega.Ω(err).Should(gomega.BeNil())
+		gomega.Ω(validUntil).Should(gomega.BeNumerically(">", time.Now().Unix()))
+
+		// Typed data must survive being written to disk
+		b, err := json.Marshal(td)
+		gomega.Ω(err).Should(gomega.BeNil())
+		td = new(tdata.TypedData)
+		gomega.Ω(json.Unmarshal(b, td)).Should(gomega.BeNil())
+
+		dh, err := tdata.DigestHash(td)
+		gomega.Ω(err).Should(gomega.BeNil())
+		sig, err := client.NewKeySigner(priv).SignDigest(context.Background(), dh)
+		gomega.Ω(err).Should(gomega.BeNil())
+		_, err = instances[0].cli.IssueTx(context.Background(), td, sig)
+		gomega.Ω(err).Should(gomega.BeNil())
+		expectBlkAccept(instances[0])
+
+		claimed, err := instances[0].cli.Claimed(context.Background(), "offline")
+		gomega.Ω(err).Should(gomega.BeNil())
+		gomega.Ω(claimed).Should(gomega.BeTrue())
 	})
 
 	// TODO: full replicate blocks between nodes
@@ -700,7 +1495,7 @@ func createIssueRawTx(i instance, utx chain.UnsignedTransaction, signer *ecdsa.P
 }
 
 func createIssueTx(i instance, input *chain.Input, signer *ecdsa.PrivateKey) {
-	td, _, err := i.cli.SuggestedFee(context.Background(), input)
+	td, _, _, err := i.cli.SuggestedFee(context.Background(), input)
 	gomega.Ω(err).Should(gomega.BeNil())
 
 	dh, err := tdata.DigestHash(td)
@@ -0,0 +1,149 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package tree
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+)
+
+const (
+	// DefaultAverageChunkSize is the average size of the chunks of
+	// [WithContentDefinedChunking] (unless it is set).
+	DefaultAverageChunkSize = 64 * 1024
+
+	// minAverageChunkSize is the smallest supported average chunk size
+	minAverageChunkSize = 256
+)
+
+// chunker splits a file into chunks.
+type chunker interface {
+	// next returns the next chunk of the file (which is only valid until the
+	// next call) or an empty chunk once the whole file was read. [last] is set
+	// if it is the last chunk.
+	next() (chunk []byte, last bool, err error)
+}
+
+func newChunker(r io.Reader, maxSize int, avgSize int) chunker {
+	if avgSize == 0 {
+		return &fixedChunker{r: r, buf: make([]byte, maxSize)}
+	}
+	return newCDCChunker(r, maxSize, avgSize)
+}
+
+// fixedChunker splits a file into chunks of the size of its buffer.
+type fixedChunker struct {
+	r    io.Reader
+	buf  []byte
+	done bool
+}
+
+func (c *fixedChunker) next() ([]byte, bool, error) {
+	if c.done {
+		return nil, true, nil
+	}
+	read, err := c.r.Read(c.buf)
+	if errors.Is(err, io.EOF) || read == 0 {
+		return nil, true, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("%w: read error", err)
+	}
+	c.done = read < len(c.buf)
+	return c.buf[:read], c.done, nil
+}
+
+// gear maps each byte to a random (but fixed) value for the rolling hash of
+// [cdcChunker]. Changing it changes the chunks of every file.
+var gear = func() (g [256]uint64) {
+	for i := range g {
+		h := sha256.Sum256([]byte{byte(i)})
+		g[i] = binary.BigEndian.Uint64(h[:8])
+	}
+	return g
+}()
+
+// cdcChunker splits a file at positions determined by its contents (FastCDC
+// with normalized chunking), so that inserting or removing bytes only changes
+// the chunks around the change.
+//
+// A chunk ends after a byte where the top bits of the gear hash of the last
+// 64 bytes are zero (more bits before [avg] and fewer after it, so that most
+// chunks are close to [avg]). Chunks are at least [min] (unless the file
+// ends) and at most the size of the buffer.
+type cdcChunker struct {
+	r   io.Reader
+	buf []byte
+	// Bytes read but not returned yet
+	start, end int
+	eof        bool
+
+	min, avg     int
+	maskS, maskL uint64
+}
+
+func newCDCChunker(r io.Reader, maxSize int, avgSize int) *cdcChunker {
+	if avgSize > maxSize/2 {
+		avgSize = maxSize / 2
+	}
+	if avgSize < minAverageChunkSize {
+		avgSize = minAverageChunkSize
+	}
+	b := bits.Len(uint(avgSize)) - 1
+	return &cdcChunker{
+		r:     r,
+		buf:   make([]byte, maxSize),
+		min:   1 << (b - 2),
+		avg:   1 << b,
+		maskS: ^uint64(0) << (64 - (b + 1)),
+		maskL: ^uint64(0) << (64 - (b - 1)),
+	}
+}
+
+func (c *cdcChunker) next() ([]byte, bool, error) {
+	c.end = copy(c.buf, c.buf[c.start:c.end])
+	c.start = 0
+	if !c.eof {
+		read, err := io.ReadFull(c.r, c.buf[c.end:])
+		c.end += read
+		switch {
+		case errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF):
+			c.eof = true
+		case err != nil:
+			return nil, false, fmt.Errorf("%w: read error", err)
+		}
+	}
+	c.start = c.cut(c.buf[:c.end])
+	return c.buf[:c.start], c.eof && c.start == c.end, nil
+}
+
+// cut returns the size of the chunk at the start of [b].
+func (c *cdcChunker) cut(b []byte) int {
+	if len(b) <= c.min {
+		return len(b)
+	}
+	normal := c.avg
+	if normal > len(b) {
+		normal = len(b)
+	}
+	var h uint64
+	i := c.min
+	for ; i < normal; i++ {
+		h = h<<1 + gear[b[i]]
+		if h&c.maskS == 0 {
+			return i + 1
+		}
+	}
+	for ; i < len(b); i++ {
+		h = h<<1 + gear[b[i]]
+		if h&c.maskL == 0 {
+			return i + 1
+		}
+	}
+	return len(b)
+}
@@ -0,0 +1,64 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package tree
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+)
+
+// CompressionGzip compresses each chunk of a file (or the [Node.Contents] of
+// a small file) with gzip before it is encrypted (if the file is encrypted).
+const CompressionGzip = "gzip"
+
+// checkCompression returns an error if [compression] is not supported.
+func checkCompression(compression string) error {
+	switch compression {
+	case "", CompressionGzip:
+		return nil
+	default:
+		return fmt.Errorf("%w: %q", ErrUnsupportedCompression, compression)
+	}
+}
+
+// compressOverhead is the most bytes [compression] adds to [n] bytes (if
+// they cannot be compressed): the gzip header and trailer and 5 bytes for
+// each stored deflate block.
+func compressOverhead(compression string, n int) int {
+	if len(compression) == 0 {
+		return 0
+	}
+	return 18 + 5*(n/(16*1024)+2)
+}
+
+func compress(b []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(b); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decompress returns the decompressed [b], which must not be larger than
+// [limit] bytes.
+func decompress(b []byte, limit uint64) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrIntegrity, err)
+	}
+	plain, err := io.ReadAll(io.LimitReader(r, int64(limit)+1))
+	switch {
+	case err != nil:
+		return nil, fmt.Errorf("%w: %v", ErrIntegrity, err)
+	case uint64(len(plain)) > limit:
+		return nil, fmt.Errorf("%w: chunk is larger than the file", ErrIntegrity)
+	}
+	return plain, nil
+}
@@ -0,0 +1,156 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package tree
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// NodeVersion is the version of the [Node]s written by [Upload].
+//
+// Version 0 files (written before versioning was introduced) only have a
+// root node that lists the keys of all chunks, so their size is limited by
+// how many keys fit in a single value.
+const NodeVersion = 1
+
+// EncodedNodeVersion is the version of the roots of encrypted or compressed
+// files (so that readers that do not support [Encryption] or
+// [Node.Compression] reject them instead of returning encoded chunks).
+const EncodedNodeVersion = 2
+
+// nodeOverhead is reserved in each [Node] for everything but its children
+// (including the [FileMeta] of the root).
+const nodeOverhead = 1024
+
+// Node is a node of the Merkle DAG of a file. Every node is stored at the
+// keccak256 hash of its JSON encoding.
+//
+// Small files are stored in the [Contents] of the root. Otherwise,
+// [Children] are the keys of the chunks of the file if [Height] is 0 or the
+// keys of the [Node]s of height [Height]-1 (in order). Only the root has
+// [Meta] (or [Encryption], if the file is encrypted) and [Compression].
+type Node struct {
+	Version     uint64      `json:"version,omitempty"`
+	Height      uint64      `json:"height,omitempty"`
+	Size        uint64      `json:"size,omitempty"`
+	Meta        *FileMeta   `json:"meta,omitempty"`
+	Encryption  *Encryption `json:"encryption,omitempty"`
+	Compression string      `json:"compression,omitempty"`
+	Contents    []byte      `json:"contents,omitempty"`
+	Children    []string    `json:"children,omitempty"`
+}
+
+// FileMeta is optional metadata of a file (the total size of the file is the
+// [Node.Size] of its root).
+type FileMeta struct {
+	Name        string `json:"name,omitempty"`
+	ContentType string `json:"contentType,omitempty"`
+	// Hex-encoded SHA-256 hash of the file
+	SHA256 string `json:"sha256,omitempty"`
+	// Unix time the file was last modified. Like every field of the root, it
+	// is part of the key of the root, so uploading the same contents with
+	// another modification time writes a new root (the chunks are still
+	// deduplicated).
+	Modified int64 `json:"modified,omitempty"`
+}
+
+// Root is the format of the root of version 0 files.
+//
+// Deprecated: Use [Node].
+type Root = Node
+
+// hashKey returns the key [b] is stored at.
+func hashKey(b []byte) string {
+	return strings.ToLower(common.Bytes2Hex(crypto.Keccak256(b)))
+}
+
+// isHashKey returns true if [key] has the format of the keys returned by
+// [hashKey].
+func isHashKey(key string) bool {
+	if len(key) != 2*common.HashLength || strings.ToLower(key) != key {
+		return false
+	}
+	_, err := hex.DecodeString(key)
+	return err == nil
+}
+
+// verify returns an error if [b] is not the value of [key].
+func verify(key string, b []byte) error {
+	if h := hashKey(b); h != key {
+		return fmt.Errorf("%w: expected %s got %s", ErrIntegrity, key, h)
+	}
+	return nil
+}
+
+// parseNode verifies and parses the [Node] at [key].
+func parseNode(key string, b []byte) (*Node, error) {
+	if err := verify(key, b); err != nil {
+		return nil, err
+	}
+	n := new(Node)
+	if err := json.Unmarshal(b, n); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidNode, err)
+	}
+	switch {
+	case n.Version > EncodedNodeVersion:
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedVersion, n.Version)
+	case n.Version == 0 && n.Height > 0:
+		return nil, fmt.Errorf("%w: version 0 node with height %d", ErrInvalidNode, n.Height)
+	case (n.Encryption != nil || len(n.Compression) > 0) != (n.Version == EncodedNodeVersion):
+		return nil, fmt.Errorf(
+			"%w: version %d node with encryption %t and compression %q",
+			ErrInvalidNode, n.Version, n.Encryption != nil, n.Compression,
+		)
+	case n.Encryption != nil && n.Meta != nil:
+		return nil, fmt.Errorf("%w: encrypted node with plaintext meta", ErrInvalidNode)
+	}
+	if err := checkCompression(n.Compression); err != nil {
+		return nil, err
+	}
+	if err := checkMeta(n.Meta); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// checkMeta returns an error if [m] (which may be nil) is invalid.
+func checkMeta(m *FileMeta) error {
+	switch {
+	case m == nil:
+		return nil
+	case len(m.SHA256) > 0 && !isSHA256(m.SHA256):
+		return fmt.Errorf("%w: invalid sha256 %q", ErrInvalidNode, m.SHA256)
+	case len(m.Name) > 0:
+		// Names are often used as file names, so they must not be paths
+		return checkName(m.Name)
+	}
+	return nil
+}
+
+// isSHA256 returns true if [s] is a lowercase hex-encoded SHA-256 hash.
+func isSHA256(s string) bool {
+	if len(s) != 2*sha256.Size || strings.ToLower(s) != s {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
+
+// maxChildren returns how many children fit in a [Node] of at most
+// [chunkSize] bytes.
+func maxChildren(chunkSize int) int {
+	// Each child is encoded as "<key>",
+	n := (chunkSize - nodeOverhead) / (2*common.HashLength + 3)
+	if n < 2 {
+		return 2
+	}
+	return n
+}
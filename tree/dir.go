@@ -0,0 +1,317 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package tree
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ava-labs/spacesvm/client"
+	"github.com/ava-labs/spacesvm/parser"
+)
+
+// Dir is a directory. Like a [Node], it is stored at the keccak256 hash of its
+// JSON encoding and must fit in a single chunk.
+type Dir struct {
+	Version uint64      `json:"version"`
+	Entries []*DirEntry `json:"entries"`
+}
+
+// DirEntry is a file or directory in a [Dir].
+type DirEntry struct {
+	Name string `json:"name"`
+	// Key of the root [Node] of the file (or of the [Dir])
+	Key string `json:"key"`
+	Dir bool   `json:"dir,omitempty"`
+	// Size of the file (or of all files under the directory)
+	Size  uint64 `json:"size"`
+	Mode  uint32 `json:"mode"`
+	Mtime int64  `json:"mtime"`
+}
+
+// parseDir verifies and parses the [Dir] at [key].
+func parseDir(key string, b []byte) (*Dir, error) {
+	if err := verify(key, b); err != nil {
+		return nil, err
+	}
+	d := new(Dir)
+	if err := json.Unmarshal(b, d); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidNode, err)
+	}
+	switch {
+	case d.Entries == nil:
+		return nil, fmt.Errorf("%w: %s", ErrNotDir, key)
+	case d.Version > NodeVersion:
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedVersion, d.Version)
+	}
+	for _, e := range d.Entries {
+		if err := checkName(e.Name); err != nil {
+			return nil, err
+		}
+	}
+	return d, nil
+}
+
+// maxNameLen is the longest name of a file or directory (in bytes).
+const maxNameLen = 255
+
+// checkName ensures [name] can only refer to an entry of the directory it is
+// in.
+func checkName(name string) error {
+	if len(name) == 0 || len(name) > maxNameLen || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("%w: %q", ErrInvalidName, name)
+	}
+	return nil
+}
+
+type pendingDir struct {
+	dir *Dir
+	// nil for the root
+	entry *DirEntry
+}
+
+type pendingFile struct {
+	chunks *fileChunks
+	entry  *DirEntry
+}
+
+// UploadDir writes the regular files and directories under [dir] to [space]
+// and returns the path of its root [Dir]. The chunks of all files are issued
+// first (chunks shared by several files are only issued once), then the root
+// of every file, and then directories from the deepest up. Symbolic links and
+// other special files are skipped.
+func UploadDir(
+	ctx context.Context, cli client.Client, signer client.Signer,
+	space string, dir string, chunkSize int, opts ...OpOption,
+) (string, error) {
+	ret := &Op{workers: DefaultWorkers}
+	ret.applyOpts(opts)
+	if err := checkCompression(ret.compression); err != nil {
+		return "", err
+	}
+
+	u, err := newUploader(ctx, ret, cli, signer, space)
+	if err != nil {
+		return "", err
+	}
+	var (
+		// Directories by depth
+		dirs  [][]*pendingDir
+		files []*pendingFile
+	)
+	root, err := u.walk(ctx, dir, chunkSize, 0, &dirs, &files)
+	if err != nil {
+		return "", err
+	}
+	if err := u.wait(ctx); err != nil {
+		return "", err
+	}
+	if err := u.checkReused(ctx); err != nil {
+		return "", err
+	}
+
+	for _, f := range files {
+		r, err := u.root(ctx, f.chunks, chunkSize, ret.dedup())
+		if err != nil {
+			return "", err
+		}
+		r.Meta = f.chunks.meta(f.entry.Name, f.entry.Mtime)
+		if err := f.chunks.seal(r, u.op.recipients); err != nil {
+			return "", err
+		}
+		b, err := marshalRoot(r, chunkSize)
+		if err != nil {
+			return "", err
+		}
+		f.entry.Key = hashKey(b)
+		if err := u.addNode(ctx, f.entry.Key, b, f.chunks.rawRootSize(b)); err != nil {
+			return "", err
+		}
+	}
+	if err := u.wait(ctx); err != nil {
+		return "", err
+	}
+
+	for depth := len(dirs) - 1; depth > 0; depth-- {
+		for _, d := range dirs[depth] {
+			b, err := marshalDir(d.dir, chunkSize)
+			if err != nil {
+				return "", err
+			}
+			d.entry.Key = hashKey(b)
+			if err := u.addNode(ctx, d.entry.Key, b, len(b)); err != nil {
+				return "", err
+			}
+		}
+		if err := u.wait(ctx); err != nil {
+			return "", err
+		}
+	}
+
+	b, err := marshalDir(root.dir, chunkSize)
+	if err != nil {
+		return "", err
+	}
+	k := hashKey(b)
+	if err := u.writeRoot(ctx, k, b, len(b), false); err != nil {
+		return "", err
+	}
+	return space + parser.Delimiter + k, nil
+}
+
+func marshalDir(d *Dir, chunkSize int) ([]byte, error) {
+	b, err := json.Marshal(d)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) > chunkSize {
+		return nil, fmt.Errorf("%w: %d entries", ErrDirTooLarge, len(d.Entries))
+	}
+	return b, nil
+}
+
+// walk adds the chunks of every file under [path] and records the
+// directories and files that must be issued once they are confirmed.
+func (u *uploader) walk(
+	ctx context.Context, path string, chunkSize int, depth int,
+	dirs *[][]*pendingDir, files *[]*pendingFile,
+) (*pendingDir, error) {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	pd := &pendingDir{dir: &Dir{Version: NodeVersion, Entries: []*DirEntry{}}}
+	if len(*dirs) == depth {
+		*dirs = append(*dirs, nil)
+	}
+	(*dirs)[depth] = append((*dirs)[depth], pd)
+	for _, e := range entries {
+		info, err := e.Info()
+		if err != nil {
+			return nil, err
+		}
+		de := &DirEntry{
+			Name:  e.Name(),
+			Mode:  uint32(info.Mode().Perm()),
+			Mtime: info.ModTime().Unix(),
+		}
+		p := filepath.Join(path, e.Name())
+		switch {
+		case info.IsDir():
+			sub, err := u.walk(ctx, p, chunkSize, depth+1, dirs, files)
+			if err != nil {
+				return nil, err
+			}
+			sub.entry = de
+			de.Dir = true
+			for _, se := range sub.dir.Entries {
+				de.Size += se.Size
+			}
+		case info.Mode().IsRegular():
+			f, err := os.Open(p)
+			if err != nil {
+				return nil, err
+			}
+			fc, err := u.addFile(ctx, f, chunkSize, &Manifest{}, nil)
+			f.Close()
+			if err != nil {
+				return nil, fmt.Errorf("%w: failed to upload %s", err, p)
+			}
+			de.Size = uint64(info.Size())
+			*files = append(*files, &pendingFile{chunks: fc, entry: de})
+		default:
+			continue
+		}
+		pd.dir.Entries = append(pd.dir.Entries, de)
+	}
+	return pd, nil
+}
+
+// DownloadDir writes the directory at [path] to [dir] (which must not exist)
+// and restores the mode and modification time of every entry.
+func DownloadDir(ctx context.Context, cli client.Client, path string, dir string, opts ...OpOption) error {
+	ret := &Op{workers: DefaultWorkers, retries: DefaultRetries}
+	ret.applyOpts(opts)
+
+	exists, b, _, err := cli.Resolve(ctx, path)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("%w:%s", ErrMissing, path)
+	}
+	// Path must be formatted correctly if made it here
+	spl := strings.Split(path, parser.Delimiter)
+	d, err := parseDir(spl[1], b)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(dir); !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("%w: %s", fs.ErrExist, dir)
+	}
+	dl := &downloader{
+		op:      ret,
+		clients: append([]client.Client{cli}, ret.clients...),
+		space:   spl[0],
+	}
+	return dl.dir(ctx, d, dir, fs.FileMode(0o755))
+}
+
+// dir creates [path] and writes the entries of [d] to it.
+func (d *downloader) dir(ctx context.Context, dir *Dir, path string, mode fs.FileMode) error {
+	if err := os.Mkdir(path, 0o700); err != nil {
+		return err
+	}
+	for i, e := range dir.Entries {
+		p := filepath.Join(path, e.Name)
+		b, err := d.fetch(ctx, e.Key, i)
+		if err != nil {
+			return err
+		}
+		if e.Dir {
+			sub, err := parseDir(e.Key, b)
+			if err != nil {
+				return err
+			}
+			d.op.report(&Event{Op: EventDownload, Key: e.Key, Index: true, Size: len(b)})
+			if err := d.dir(ctx, sub, p, fs.FileMode(e.Mode)); err != nil {
+				return err
+			}
+		} else {
+			r, err := parseNode(e.Key, b)
+			if err != nil {
+				return err
+			}
+			if err := d.writeFile(ctx, e.Key, r, p, fs.FileMode(e.Mode)); err != nil {
+				return fmt.Errorf("%w: failed to download %s", err, p)
+			}
+		}
+		mtime := time.Unix(e.Mtime, 0)
+		if err := os.Chtimes(p, mtime, mtime); err != nil {
+			return err
+		}
+	}
+	return os.Chmod(path, mode.Perm())
+}
+
+// writeFile creates [path] and writes the file with root [r] (at [key]) to
+// it.
+func (d *downloader) writeFile(ctx context.Context, key string, r *Node, path string, mode fs.FileMode) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := d.file(ctx, key, r, f); err != nil {
+		return err
+	}
+	return f.Chmod(mode.Perm())
+}
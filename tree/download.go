@@ -0,0 +1,238 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package tree
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"time"
+
+	"github.com/ava-labs/spacesvm/client"
+	"github.com/ava-labs/spacesvm/parser"
+)
+
+const retryDelay = 250 * time.Millisecond
+
+// downloader fetches the chunks of a file with [Op.workers] goroutines. At
+// most 2*[Op.workers] chunks are held in memory while waiting for earlier
+// chunks to be written.
+type downloader struct {
+	op      *Op
+	clients []client.Client
+	space   string
+
+	// Bytes written so far
+	written uint64
+
+	// Cipher of the file being written (if it is encrypted), the index of its
+	// next chunk, its [Node.Compression], and the value of [written] once it
+	// is written
+	cipher      *fileCipher
+	chunk       uint64
+	compression string
+	end         uint64
+}
+
+type chunkResult struct {
+	i   int
+	b   []byte
+	err error
+}
+
+// file writes the file with root [r] (at [key]) to [f] (decrypting and
+// decompressing it if needed) and verifies its size (and hash, if [r] has a
+// [FileMeta.SHA256]).
+func (d *downloader) file(ctx context.Context, key string, r *Node, f io.Writer) error {
+	start := d.written
+	d.cipher, d.chunk, d.compression, d.end = nil, 0, r.Compression, start+r.Size
+	if r.Encryption != nil {
+		priv, err := d.op.privateKey()
+		if err != nil {
+			return err
+		}
+		c, err := openRoot(r, priv)
+		if err != nil {
+			return err
+		}
+		d.cipher = c
+	}
+	var h hash.Hash
+	if r.Meta != nil && len(r.Meta.SHA256) > 0 {
+		h = sha256.New()
+		f = io.MultiWriter(f, h)
+	}
+	switch {
+	// Use small file optimization
+	case len(r.Contents) > 0:
+		contents, err := d.open(r.Contents)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(contents); err != nil {
+			return err
+		}
+		d.written += uint64(len(contents))
+		d.op.report(&Event{Op: EventDownload, Key: key, Root: true, Size: len(contents)})
+	case len(r.Children) > 0:
+		if err := d.node(ctx, r, f); err != nil {
+			return err
+		}
+	}
+	if written := d.written - start; r.Version > 0 && written != r.Size {
+		return fmt.Errorf("%w: expected %d bytes got %d", ErrIntegrity, r.Size, written)
+	}
+	if h != nil {
+		if sum := hex.EncodeToString(h.Sum(nil)); sum != r.Meta.SHA256 {
+			return fmt.Errorf("%w: expected sha256 %s got %s", ErrIntegrity, r.Meta.SHA256, sum)
+		}
+	}
+	return nil
+}
+
+// node writes the chunks under [n] to [f]. Index nodes are fetched (and
+// verified) as they are reached.
+func (d *downloader) node(ctx context.Context, n *Node, f io.Writer) error {
+	if n.Height == 0 {
+		return d.run(ctx, n.Children, f)
+	}
+	for i, k := range n.Children {
+		b, err := d.fetch(ctx, k, i)
+		if err != nil {
+			return err
+		}
+		child, err := parseNode(k, b)
+		if err != nil {
+			return err
+		}
+		if child.Height != n.Height-1 || len(child.Children) == 0 {
+			return fmt.Errorf("%w: %s is not a node of height %d", ErrInvalidNode, k, n.Height-1)
+		}
+		d.op.report(&Event{Op: EventDownload, Key: k, Index: true, Size: len(b)})
+		if err := d.node(ctx, child, f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// run writes the chunks at [keys] to [f].
+func (d *downloader) run(ctx context.Context, keys []string, f io.Writer) error {
+	workers := d.op.workers
+	if workers < 1 {
+		workers = 1
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	jobs := make(chan int)
+	defer close(jobs)
+	results := make(chan *chunkResult, workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			for i := range jobs {
+				b, err := d.fetch(ctx, keys[i], i)
+				select {
+				case results <- &chunkResult{i: i, b: b, err: err}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	var (
+		window   = 2 * workers
+		pending  = map[int][]byte{}
+		next     int
+		assigned int
+	)
+	for next < len(keys) {
+		// Only assign work when there is room in the window
+		var assign chan<- int
+		if assigned < len(keys) && assigned < next+window {
+			assign = jobs
+		}
+		select {
+		case assign <- assigned:
+			assigned++
+		case res := <-results:
+			if res.err != nil {
+				return res.err
+			}
+			pending[res.i] = res.b
+			for b, ok := pending[next]; ok; b, ok = pending[next] {
+				b, err := d.open(b)
+				if err != nil {
+					return err
+				}
+				if _, err := f.Write(b); err != nil {
+					return err
+				}
+				d.written += uint64(len(b))
+				d.op.report(&Event{Op: EventDownload, Key: keys[next], Size: len(b)})
+				delete(pending, next)
+				next++
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// open decrypts and decompresses (if needed) the next chunk [b] of the file
+// being written.
+func (d *downloader) open(b []byte) ([]byte, error) {
+	i := d.chunk
+	d.chunk++
+	if d.cipher != nil {
+		var err error
+		b, err = d.cipher.open(i, b)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(d.compression) == 0 {
+		return b, nil
+	}
+	// The file can't be larger than its size
+	return decompress(b, d.end-d.written)
+}
+
+// fetch resolves and verifies [key], starting with client [i] (mod the number
+// of clients) and retrying up to [Op.retries] times with the next client.
+func (d *downloader) fetch(ctx context.Context, key string, i int) ([]byte, error) {
+	chunk := d.space + parser.Delimiter + key
+	var err error
+	for attempt := 0; attempt <= d.op.retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(retryDelay * time.Duration(attempt)):
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+		cli := d.clients[(i+attempt)%len(d.clients)]
+		var (
+			exists bool
+			b      []byte
+		)
+		exists, b, _, err = cli.Resolve(ctx, chunk)
+		switch {
+		case err != nil:
+		case !exists:
+			err = fmt.Errorf("%w:%s", ErrMissing, chunk)
+		default:
+			err = verify(key, b)
+		}
+		if err == nil {
+			return b, nil
+		}
+	}
+	return nil, err
+}
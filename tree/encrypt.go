@@ -0,0 +1,123 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package tree
+
+import (
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ava-labs/spacesvm/client"
+)
+
+// Encryption is the key of an encrypted file (wrapped to each recipient).
+//
+// Every chunk (or the [Node.Contents] of a small file) is encrypted with
+// AES-256-GCM under a key that is unique to the file, using its index in the
+// file as the nonce. The [FileMeta] of the file is encrypted in [Meta]. Index
+// nodes, the [Node.Size] of every node, and the entries of a [Dir] are not
+// encrypted.
+type Encryption struct {
+	Recipients []*client.Recipient `json:"recipients"`
+	Meta       []byte              `json:"meta,omitempty"`
+}
+
+// metaNonce is the first byte of the nonce of [Encryption.Meta] (the nonces of
+// chunks start with 0).
+const metaNonce = 1
+
+// fileCipher encrypts (or decrypts) the chunks of a single file.
+type fileCipher struct {
+	aead cipher.AEAD
+}
+
+func newFileCipher(key []byte) (*fileCipher, error) {
+	aead, err := client.NewAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	return &fileCipher{aead: aead}, nil
+}
+
+func (c *fileCipher) nonce(i uint64, prefix byte) []byte {
+	nonce := make([]byte, c.aead.NonceSize())
+	nonce[0] = prefix
+	binary.BigEndian.PutUint64(nonce[len(nonce)-8:], i)
+	return nonce
+}
+
+func (c *fileCipher) seal(i uint64, b []byte) []byte {
+	return c.aead.Seal(nil, c.nonce(i, 0), b, nil)
+}
+
+func (c *fileCipher) open(i uint64, b []byte) ([]byte, error) {
+	plain, err := c.aead.Open(nil, c.nonce(i, 0), b, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to decrypt chunk %d", ErrIntegrity, i)
+	}
+	return plain, nil
+}
+
+// overhead is the size difference between an encrypted chunk and its
+// plaintext.
+func (c *fileCipher) overhead() int {
+	if c == nil {
+		return 0
+	}
+	return c.aead.Overhead()
+}
+
+// sealRoot wraps the key of the file to [recipients] and moves the
+// [Node.Meta] of [r] into its [Encryption].
+func (c *fileCipher) sealRoot(r *Node, key []byte, recipients []*ecdsa.PublicKey) error {
+	wrapped, err := client.WrapKey(key, recipients)
+	if err != nil {
+		return err
+	}
+	r.Version = EncodedNodeVersion
+	r.Encryption = &Encryption{Recipients: wrapped}
+	if r.Meta != nil {
+		b, err := json.Marshal(r.Meta)
+		if err != nil {
+			return err
+		}
+		r.Encryption.Meta = c.aead.Seal(nil, c.nonce(0, metaNonce), b, nil)
+		r.Meta = nil
+	}
+	return nil
+}
+
+// openRoot returns the cipher of the encrypted file with root [r] and
+// decrypts its [FileMeta] into [r.Meta].
+func openRoot(r *Node, priv *ecdsa.PrivateKey) (*fileCipher, error) {
+	if priv == nil {
+		return nil, ErrEncrypted
+	}
+	key, err := client.UnwrapKey(r.Encryption.Recipients, priv)
+	if err != nil {
+		return nil, err
+	}
+	c, err := newFileCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(r.Encryption.Meta) == 0 {
+		return c, nil
+	}
+	b, err := c.aead.Open(nil, c.nonce(0, metaNonce), r.Encryption.Meta, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to decrypt meta", ErrIntegrity)
+	}
+	m := new(FileMeta)
+	if err := json.Unmarshal(b, m); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidNode, err)
+	}
+	if err := checkMeta(m); err != nil {
+		return nil, err
+	}
+	r.Meta = m
+	return c, nil
+}
@@ -8,6 +8,17 @@ import (
 )
 
 var (
-	ErrEmpty   = errors.New("file is empty")
-	ErrMissing = errors.New("required file is missing")
+	ErrEmpty                  = errors.New("file is empty")
+	ErrMissing                = errors.New("required file is missing")
+	ErrManifestMismatch       = errors.New("manifest does not match upload")
+	ErrIntegrity              = errors.New("value does not match key")
+	ErrInvalidNode            = errors.New("invalid node")
+	ErrUnsupportedVersion     = errors.New("unsupported node version")
+	ErrNotDir                 = errors.New("not a directory")
+	ErrInvalidName            = errors.New("invalid name")
+	ErrDirTooLarge            = errors.New("directory does not fit in a chunk")
+	ErrShared                 = errors.New("file is part of another file or directory")
+	ErrRootTooLarge           = errors.New("root does not fit in a chunk")
+	ErrEncrypted              = errors.New("file is encrypted")
+	ErrUnsupportedCompression = errors.New("unsupported compression")
 )
@@ -0,0 +1,156 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package tree
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+
+	"github.com/ava-labs/spacesvm/chain"
+	"github.com/ava-labs/spacesvm/client"
+)
+
+// DefaultGCMinAge is how long a key must not have been updated before [GC]
+// deletes it (so that chunks of uploads in progress are not deleted before
+// their root is written).
+const DefaultGCMinAge = time.Hour
+
+// GCReport describes the orphaned keys found by [GC].
+type GCReport struct {
+	// Number of content-addressed keys in the space
+	Keys int `json:"keys"`
+	// Number of roots of files and directories (see [GC])
+	Roots int `json:"roots"`
+	// Content-addressed keys that are not reachable from any root
+	Orphans []string `json:"orphans"`
+	// Total size of [Orphans]
+	Size uint64 `json:"size"`
+
+	// Units and expiry (unix) of the space before and after [Orphans] are
+	// deleted
+	Units     uint64 `json:"units"`
+	Expiry    uint64 `json:"expiry"`
+	NewUnits  uint64 `json:"newUnits"`
+	NewExpiry uint64 `json:"newExpiry"`
+
+	// Cost of the [chain.DeleteTx]s (0 with [WithDryRun])
+	TotalCost uint64 `json:"totalCost,omitempty"`
+}
+
+// WithDryRun makes [GC] only report the orphaned keys of a space.
+func WithDryRun() OpOption {
+	return func(op *Op) { op.dryRun = true }
+}
+
+// WithMinAge sets how long a key must not have been updated before [GC]
+// deletes it (defaults to [DefaultGCMinAge]).
+func WithMinAge(d time.Duration) OpOption {
+	return func(op *Op) { op.minAge = d }
+}
+
+// GC deletes the content-addressed keys of [space] that are not reachable
+// from any root (like the chunks of a root that was deleted with
+// [chain.DeleteTx] instead of [Delete], or of an upload that was never
+// completed) and returns what was deleted. [signer] is not used (and may be
+// nil) with [WithDryRun].
+//
+// Every value stored at the hash of its contents is fetched (except chunks
+// that are referenced by a node fetched before them) to find the [Node]s and
+// [Dir]s of the space. Roots are [Dir]s, version 0 nodes, and nodes with a
+// field that index nodes never have ([Node.Meta], [Node.Encryption],
+// [Node.Contents], or [Node.Compression]). Everything else that is not
+// reachable from a root (including index nodes) is deleted, except values
+// that are not stored at their hash and keys updated within [WithMinAge].
+// Deletions are issued [WithWorkers] at a time.
+//
+// Chunks that an [Upload] finds in the space are reused without being written
+// again, so they keep their update time and may be deleted before the root
+// referencing them is written. Uploads check that reused chunks still exist
+// before writing their root (and fail otherwise), but GC should not run while
+// files are uploaded to [space].
+func GC(
+	ctx context.Context, cli client.Client, signer client.Signer, space string, opts ...OpOption,
+) (*GCReport, error) {
+	ret := &Op{workers: DefaultWorkers, minAge: DefaultGCMinAge}
+	ret.applyOpts(opts)
+
+	info, values, err := cli.Info(ctx, space)
+	if err != nil {
+		return nil, err
+	}
+	g := newGraph(ret, cli, space)
+	if err := g.walk(ctx, values); err != nil {
+		return nil, err
+	}
+
+	r := &GCReport{Keys: g.keys, Roots: len(g.roots), Orphans: []string{}, Units: info.Units, Expiry: info.Expiry}
+	live := g.reachable(g.roots)
+	cutoff := uint64(time.Now().Add(-ret.minAge).Unix())
+	ops := []*chain.ProjectedOp{}
+	for _, v := range g.values {
+		if _, ok := live[v.Key]; ok || v.ValueMeta.Updated > cutoff {
+			continue
+		}
+		r.Orphans = append(r.Orphans, v.Key)
+		r.Size += v.ValueMeta.Size
+		ops = append(ops, &chain.ProjectedOp{Typ: chain.Delete, Key: v.Key, Size: v.ValueMeta.Size})
+	}
+	p, err := cli.ProjectSpace(ctx, space, ops, 0)
+	if err != nil {
+		return nil, err
+	}
+	r.NewUnits, r.NewExpiry = p.Units, p.Expiry
+	if ret.dryRun || len(r.Orphans) == 0 {
+		return r, nil
+	}
+	r.TotalCost, err = g.delete(ctx, signer, r.Orphans)
+	return r, err
+}
+
+// delete issues a [chain.DeleteTx] for each of [keys] ([Op.workers] at a time)
+// and returns their total cost.
+func (g *graph) delete(ctx context.Context, signer client.Signer, keys []string) (uint64, error) {
+	workers := g.op.workers
+	if workers < 1 {
+		workers = 1
+	}
+	txOpts := append([]client.OpOption{client.WithPollTx()}, g.op.txOpts...)
+	var totalCost uint64
+	for start := 0; start < len(keys); start += workers {
+		end := start + workers
+		if end > len(keys) {
+			end = len(keys)
+		}
+		batch := keys[start:end]
+
+		var (
+			wg    sync.WaitGroup
+			txIDs = make([]ids.ID, len(batch))
+			costs = make([]uint64, len(batch))
+			errs  = make([]error, len(batch))
+		)
+		for i, k := range batch {
+			wg.Add(1)
+			go func(i int, k string) {
+				defer wg.Done()
+				tx := &chain.DeleteTx{BaseTx: &chain.BaseTx{}, Space: g.space, Key: k}
+				txIDs[i], costs[i], errs[i] = client.SignIssueRawTx(ctx, g.cli, tx, signer, txOpts...)
+			}(i, k)
+		}
+		wg.Wait()
+		for i, k := range batch {
+			if errs[i] != nil {
+				return totalCost, errs[i]
+			}
+			totalCost += costs[i]
+			g.op.report(&Event{
+				Op: EventDelete, Key: k, TxID: txIDs[i], Cost: costs[i], TotalCost: totalCost,
+			})
+		}
+	}
+	return totalCost, nil
+}
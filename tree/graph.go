@@ -0,0 +1,195 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package tree
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/ava-labs/spacesvm/chain"
+	"github.com/ava-labs/spacesvm/client"
+	"github.com/ava-labs/spacesvm/parser"
+)
+
+// graph is the [Node]s and [Dir]s of a space and the keys they reference.
+type graph struct {
+	op    *Op
+	cli   client.Client
+	space string
+
+	// Number of content-addressed keys
+	keys int
+	// Values that are stored at their hash (or referenced as chunks)
+	values []*chain.KeyValueMeta
+	// Keys referenced as chunks (by nodes of height 0)
+	chunks map[string]struct{}
+	// Keys referenced by each [Node] and [Dir]
+	children map[string][]string
+	// Keys of the roots of files and directories (see [isRoot])
+	roots []string
+	// Keys of every [Node] and [Dir]
+	nodes []string
+}
+
+func newGraph(op *Op, cli client.Client, space string) *graph {
+	return &graph{
+		op:       op,
+		cli:      cli,
+		space:    space,
+		chunks:   map[string]struct{}{},
+		children: map[string][]string{},
+	}
+}
+
+// walk fetches the content-addressed [values] (smallest first, so that most
+// nodes are fetched before the chunks they reference) [Op.workers] at a time.
+func (g *graph) walk(ctx context.Context, values []*chain.KeyValueMeta) error {
+	hashed := []*chain.KeyValueMeta{}
+	for _, v := range values {
+		if isHashKey(v.Key) {
+			hashed = append(hashed, v)
+		}
+	}
+	g.keys = len(hashed)
+	sort.SliceStable(hashed, func(i, j int) bool {
+		return hashed[i].ValueMeta.Size < hashed[j].ValueMeta.Size
+	})
+
+	workers := g.op.workers
+	if workers < 1 {
+		workers = 1
+	}
+	for start := 0; start < len(hashed); start += workers {
+		end := start + workers
+		if end > len(hashed) {
+			end = len(hashed)
+		}
+		batch := []*chain.KeyValueMeta{}
+		for _, v := range hashed[start:end] {
+			if _, ok := g.chunks[v.Key]; ok {
+				// Chunks don't reference other keys, so they don't need to be
+				// fetched
+				g.values = append(g.values, v)
+				continue
+			}
+			batch = append(batch, v)
+		}
+
+		var (
+			wg      sync.WaitGroup
+			fetched = make([][]byte, len(batch))
+			errs    = make([]error, len(batch))
+		)
+		for i, v := range batch {
+			wg.Add(1)
+			go func(i int, v *chain.KeyValueMeta) {
+				defer wg.Done()
+				var exists bool
+				exists, fetched[i], _, errs[i] = g.cli.Resolve(ctx, g.space+parser.Delimiter+v.Key)
+				if errs[i] == nil && !exists {
+					// Deleted since the space was listed
+					fetched[i] = nil
+				}
+			}(i, v)
+		}
+		wg.Wait()
+		for i, v := range batch {
+			if errs[i] != nil {
+				return errs[i]
+			}
+			if fetched[i] == nil {
+				continue
+			}
+			if err := g.add(v, fetched[i]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// add records the value [b] of [v] (unless it is not stored at its hash) and
+// the keys it references if it is a [Node] or a [Dir]. Nodes of an
+// unsupported version (or compression) return an error, since the keys they
+// reference are unknown.
+func (g *graph) add(v *chain.KeyValueMeta, b []byte) error {
+	if verify(v.Key, b) != nil {
+		// Not stored at its hash
+		return nil
+	}
+	g.values = append(g.values, v)
+	d, err := parseDir(v.Key, b)
+	switch {
+	case err == nil:
+		g.roots = append(g.roots, v.Key)
+		g.nodes = append(g.nodes, v.Key)
+		for _, e := range d.Entries {
+			g.children[v.Key] = append(g.children[v.Key], e.Key)
+		}
+		return nil
+	case errors.Is(err, ErrUnsupportedVersion):
+		return err
+	}
+	n, err := parseNode(v.Key, b)
+	switch {
+	case err == nil:
+		if isRoot(n) {
+			g.roots = append(g.roots, v.Key)
+		}
+		g.nodes = append(g.nodes, v.Key)
+		g.children[v.Key] = n.Children
+		if n.Height == 0 {
+			for _, k := range n.Children {
+				g.chunks[k] = struct{}{}
+			}
+		}
+		return nil
+	case errors.Is(err, ErrUnsupportedVersion) || errors.Is(err, ErrUnsupportedCompression):
+		return err
+	}
+	return nil
+}
+
+// isRoot returns true if [n] can only be the root of a file. Index nodes are
+// never version 0 and only have a height, a size, and children.
+func isRoot(n *Node) bool {
+	return n.Version == 0 || n.Meta != nil || n.Encryption != nil || len(n.Contents) > 0 || len(n.Compression) > 0
+}
+
+// reachable returns the keys reachable from [roots] (including the roots).
+func (g *graph) reachable(roots []string) map[string]struct{} {
+	live := map[string]struct{}{}
+	stack := append([]string{}, roots...)
+	for len(stack) > 0 {
+		k := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if _, ok := live[k]; ok {
+			continue
+		}
+		live[k] = struct{}{}
+		stack = append(stack, g.children[k]...)
+	}
+	return live
+}
+
+// unreferenced returns the keys of the [Node]s and [Dir]s that no other node
+// references (the roots of files and directories, and index nodes left by
+// an interrupted [Delete] or upload).
+func (g *graph) unreferenced() []string {
+	referenced := map[string]struct{}{}
+	for _, children := range g.children {
+		for _, k := range children {
+			referenced[k] = struct{}{}
+		}
+	}
+	keys := []string{}
+	for _, k := range g.nodes {
+		if _, ok := referenced[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
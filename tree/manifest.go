@@ -0,0 +1,102 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package tree
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/ava-labs/avalanchego/ids"
+
+	"github.com/ava-labs/spacesvm/client"
+)
+
+// hasKeysBatch is the most keys checked with a single [client.HasKeys] call.
+const hasKeysBatch = 1024
+
+// Manifest records the progress of an [Upload] so that it can be resumed
+// after a failure (see [WithManifest]). The manifest of an encrypted upload
+// includes the key of the file, so it must be kept private.
+type Manifest struct {
+	Space     string `json:"space"`
+	ChunkSize int    `json:"chunkSize"`
+	// Average size of content-defined chunks (0 if chunks are fixed-size)
+	AvgChunkSize int    `json:"avgChunkSize,omitempty"`
+	Compression  string `json:"compression,omitempty"`
+	// Keccak256 hash of the file (populated once the whole file was read)
+	FileHash string `json:"fileHash,omitempty"`
+	// Chunks of the file (in order)
+	Chunks []*ManifestChunk `json:"chunks"`
+	// Path of the root (populated once it is confirmed)
+	Path string `json:"path,omitempty"`
+	// Key of the file (if it is encrypted)
+	Key []byte `json:"key,omitempty"`
+}
+
+type ManifestChunk struct {
+	Key string `json:"key"`
+	// ID of the last tx that wrote the chunk (if issued)
+	TxID      ids.ID `json:"txId"`
+	Confirmed bool   `json:"confirmed"`
+}
+
+// LoadManifest reads the manifest at [path]. If there is no manifest at
+// [path], the returned error wraps [os.ErrNotExist].
+func LoadManifest(path string) (*Manifest, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	m := new(Manifest)
+	if err := json.Unmarshal(b, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Save atomically writes [m] to [path].
+func (m *Manifest) Save(path string) error {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// existing returns which chunks of [m] already have a value in [m.Space].
+func (m *Manifest) existing(ctx context.Context, cli client.Client) (map[string]bool, error) {
+	keys := make([]string, len(m.Chunks))
+	for i, c := range m.Chunks {
+		keys[i] = c.Key
+	}
+	return hasKeys(ctx, cli, m.Space, keys)
+}
+
+// hasKeys returns which of [keys] have a value in [space].
+func hasKeys(ctx context.Context, cli client.Client, space string, keys []string) (map[string]bool, error) {
+	existing := map[string]bool{}
+	for start := 0; start < len(keys); start += hasKeysBatch {
+		end := start + hasKeysBatch
+		if end > len(keys) {
+			end = len(keys)
+		}
+		exists, err := cli.HasKeys(ctx, space, keys[start:end])
+		if err != nil {
+			return nil, err
+		}
+		for i, k := range keys[start:end] {
+			existing[k] = exists[i]
+		}
+	}
+	return existing, nil
+}
@@ -6,204 +6,526 @@ package tree
 import (
 	"context"
 	"crypto/ecdsa"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"os"
 	"strings"
+	"time"
 
-	"github.com/ava-labs/avalanchego/utils/units"
-	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/crypto"
-	"github.com/fatih/color"
+	"github.com/ava-labs/avalanchego/ids"
 
 	"github.com/ava-labs/spacesvm/chain"
 	"github.com/ava-labs/spacesvm/client"
 	"github.com/ava-labs/spacesvm/parser"
 )
 
-type Root struct {
-	Contents []byte   `json:"contents"`
-	Children []string `json:"children"`
+const (
+	EventUpload   = "upload"
+	EventReissue  = "reissue"
+	EventSkip     = "skip"
+	EventDownload = "download"
+	EventDelete   = "delete"
+)
+
+// Event describes the progress of an [Upload], [Download], or [Delete].
+type Event struct {
+	Op    string `json:"op"`
+	Key   string `json:"key"`
+	Root  bool   `json:"root,omitempty"`
+	Index bool   `json:"index,omitempty"`
+	Size  int    `json:"size,omitempty"`
+
+	// Populated when a tx is issued
+	TxID      ids.ID `json:"txId"`
+	Cost      uint64 `json:"cost,omitempty"`
+	TotalCost uint64 `json:"totalCost,omitempty"`
+	// Cost saved by compression (see [WithCompression])
+	Saved      uint64 `json:"saved,omitempty"`
+	TotalSaved uint64 `json:"totalSaved,omitempty"`
+}
+
+const (
+	DefaultWorkers = 4
+	DefaultRetries = 2
+
+	// DefaultUploadBlocks is the number of (target size) blocks worth of
+	// chunks that can be unconfirmed during an [Upload]
+	DefaultUploadBlocks = 4
+)
+
+type Op struct {
+	progress func(*Event)
+	txOpts   []client.OpOption
+
+	workers int
+
+	// Upload params
+	budget       uint64
+	manifest     string
+	fanout       int
+	name         string
+	contentType  string
+	recipients   []*ecdsa.PublicKey
+	compression  string
+	avgChunkSize int
+
+	// GC params
+	dryRun bool
+	minAge time.Duration
+
+	// Download params
+	retries int
+	clients []client.Client
+	loadKey func() (*ecdsa.PrivateKey, error)
+	priv    *ecdsa.PrivateKey
+}
+
+type OpOption func(*Op)
+
+func (op *Op) applyOpts(opts []OpOption) {
+	for _, opt := range opts {
+		opt(op)
+	}
+}
+
+func (op *Op) report(e *Event) {
+	if op.progress != nil {
+		op.progress(e)
+	}
+}
+
+// WithProgress calls [f] after each chunk is processed.
+func WithProgress(f func(*Event)) OpOption {
+	return func(op *Op) { op.progress = f }
+}
+
+// WithWorkers sets the number of chunks that are issued or downloaded
+// concurrently.
+func WithWorkers(n int) OpOption {
+	return func(op *Op) { op.workers = n }
+}
+
+// WithUploadBudget sets the maximum load units of chunks that can be
+// unconfirmed during an [Upload] (defaults to [DefaultUploadBlocks] blocks).
+func WithUploadBudget(units uint64) OpOption {
+	return func(op *Op) { op.budget = units }
+}
+
+// WithFanout sets the most children of each [Node] written by [Upload]
+// (defaults to as many as fit in a chunk).
+func WithFanout(n int) OpOption {
+	return func(op *Op) { op.fanout = n }
+}
+
+// WithManifest records the progress of an [Upload] in a [Manifest] at [path].
+// If there already is a manifest at [path], the upload is resumed and chunks
+// that already exist in the space are not issued again.
+func WithManifest(path string) OpOption {
+	return func(op *Op) { op.manifest = path }
+}
+
+// WithFileName sets the [FileMeta.Name] of the file written by [Upload]
+// (defaults to the base name of the file if it has a Name method, like
+// [os.File]).
+func WithFileName(name string) OpOption {
+	return func(op *Op) { op.name = name }
+}
+
+// WithContentType sets the [FileMeta.ContentType] of the file written by
+// [Upload] (defaults to the type of its extension or, if unknown, the type
+// detected from its contents).
+func WithContentType(t string) OpOption {
+	return func(op *Op) { op.contentType = t }
+}
+
+// WithEncryption encrypts the files written by [Upload] or [UploadDir] so
+// that only [recipients] can read them (see [Encryption]).
+func WithEncryption(recipients ...*ecdsa.PublicKey) OpOption {
+	return func(op *Op) { op.recipients = append(op.recipients, recipients...) }
+}
+
+// WithCompression compresses each chunk of the files written by [Upload] or
+// [UploadDir] with [compression] (see [CompressionGzip]), so that they cost
+// fewer fee units. [Download] decompresses them transparently.
+func WithCompression(compression string) OpOption {
+	return func(op *Op) { op.compression = compression }
+}
+
+// WithContentDefinedChunking splits the files written by [Upload] or
+// [UploadDir] where their contents match a rolling hash (into chunks of
+// [avgSize] bytes on average, or [DefaultAverageChunkSize] if it is 0)
+// instead of every [chunkSize] bytes. Inserting or removing bytes then only
+// changes the chunks around the change, and chunks (and index nodes) that
+// already exist in the space are not issued again, so uploading a new version
+// of a file only pays for the chunks that changed. Chunks of encrypted files
+// are never shared.
+func WithContentDefinedChunking(avgSize int) OpOption {
+	return func(op *Op) {
+		if avgSize <= 0 {
+			avgSize = DefaultAverageChunkSize
+		}
+		op.avgChunkSize = avgSize
+	}
+}
+
+// WithDecryptionKey decrypts encrypted files with the key returned by [load]
+// (which is only called once an encrypted file is reached). Without it,
+// reading an encrypted file returns [ErrEncrypted].
+func WithDecryptionKey(load func() (*ecdsa.PrivateKey, error)) OpOption {
+	return func(op *Op) { op.loadKey = load }
+}
+
+// WithRetries sets the number of times a chunk download is retried (each
+// attempt uses the next client).
+func WithRetries(n int) OpOption {
+	return func(op *Op) { op.retries = n }
+}
+
+// WithClients downloads chunks from [clis] in addition to the client passed
+// to [Download] (round-robin).
+func WithClients(clis ...client.Client) OpOption {
+	return func(op *Op) { op.clients = append(op.clients, clis...) }
+}
+
+// WithTxOptions passes [opts] to [client.SignIssueRawTx] for each issued tx.
+// Chunks issued by [Upload] are confirmed asynchronously, so [opts] should not
+// include [client.WithPollTx].
+func WithTxOptions(opts ...client.OpOption) OpOption {
+	return func(op *Op) { op.txOpts = append(op.txOpts, opts...) }
 }
 
+// Upload writes [f] to [space] in chunks of [chunkSize] and returns the path
+// of the root [Node] of its Merkle DAG. Chunks are issued without waiting for
+// earlier chunks to be confirmed and each level of the DAG is only issued once
+// the level below it is confirmed. The root includes the [FileMeta] of [f].
 func Upload(
-	ctx context.Context, cli client.Client, priv *ecdsa.PrivateKey,
-	space string, f io.Reader, chunkSize int,
+	ctx context.Context, cli client.Client, signer client.Signer,
+	space string, f io.Reader, chunkSize int, opts ...OpOption,
 ) (string, error) {
-	hashes := []string{}
-	chunk := make([]byte, chunkSize)
-	shouldExit := false
-	opts := []client.OpOption{client.WithPollTx()}
-	totalCost := uint64(0)
-	uploaded := map[string]struct{}{}
-	for !shouldExit {
-		read, err := f.Read(chunk)
-		if errors.Is(err, io.EOF) || read == 0 {
-			break
-		}
-		if err != nil {
-			return "", fmt.Errorf("%w: read error", err)
-		}
-		if read < chunkSize {
-			shouldExit = true
-			chunk = chunk[:read]
+	ret := &Op{workers: DefaultWorkers}
+	ret.applyOpts(opts)
+	if err := checkCompression(ret.compression); err != nil {
+		return "", err
+	}
 
-			// Use small file optimization
-			if len(hashes) == 0 {
-				break
-			}
-		}
-		k := strings.ToLower(common.Bytes2Hex(crypto.Keccak256(chunk)))
-		if _, ok := uploaded[k]; ok {
-			color.Yellow("already uploaded k=%s, skipping", k)
-		} else {
-			tx := &chain.SetTx{
-				BaseTx: &chain.BaseTx{},
-				Space:  space,
-				Key:    k,
-				Value:  chunk,
-			}
-			txID, cost, err := client.SignIssueRawTx(ctx, cli, tx, priv, opts...)
-			if err != nil {
-				return "", err
-			}
-			totalCost += cost
-			color.Yellow("uploaded k=%s txID=%s cost=%d totalCost=%d", k, txID, cost, totalCost)
-			uploaded[k] = struct{}{}
-		}
-		hashes = append(hashes, k)
+	m, existing, err := ret.loadManifest(ctx, cli, space, chunkSize)
+	if err != nil {
+		return "", err
+	}
+	u, err := newUploader(ctx, ret, cli, signer, space)
+	if err != nil {
+		return "", err
 	}
+	u.checkpoint = func() error { return ret.saveManifest(m) }
 
-	r := &Root{}
-	if len(hashes) == 0 {
-		if len(chunk) == 0 {
-			return "", ErrEmpty
-		}
-		r.Contents = chunk
-	} else {
-		r.Children = hashes
+	fc, err := u.addFile(ctx, f, chunkSize, m, existing)
+	if err != nil {
+		return "", err
+	}
+	if err := u.wait(ctx); err != nil {
+		return "", err
+	}
+	if len(m.Chunks) != len(fc.keys) || (len(m.FileHash) > 0 && m.FileHash != fc.hash) {
+		return "", fmt.Errorf("%w: file hash changed", ErrManifestMismatch)
+	}
+	m.FileHash = fc.hash
+	if err := ret.saveManifest(m); err != nil {
+		return "", err
 	}
 
-	rb, err := json.Marshal(r)
+	if len(fc.keys) == 0 && len(fc.contents) == 0 {
+		return "", ErrEmpty
+	}
+	if err := u.checkReused(ctx); err != nil {
+		return "", err
+	}
+	r, err := u.root(ctx, fc, chunkSize, existing != nil || ret.dedup())
 	if err != nil {
 		return "", err
 	}
-	rk := strings.ToLower(common.Bytes2Hex(crypto.Keccak256(rb)))
-	tx := &chain.SetTx{
-		BaseTx: &chain.BaseTx{},
-		Space:  space,
-		Key:    rk,
-		Value:  rb,
+	name, modified := fileInfo(f)
+	if len(ret.name) > 0 {
+		if err := checkName(ret.name); err != nil {
+			return "", err
+		}
+		name = ret.name
+	}
+	r.Meta = fc.meta(name, modified)
+	if len(ret.contentType) > 0 {
+		r.Meta.ContentType = ret.contentType
 	}
-	txID, cost, err := client.SignIssueRawTx(ctx, cli, tx, priv, opts...)
+	if err := fc.seal(r, ret.recipients); err != nil {
+		return "", err
+	}
+	rb, err := marshalRoot(r, chunkSize)
 	if err != nil {
 		return "", err
 	}
-	totalCost += cost
-	color.Yellow("uploaded root=%s txID=%s cost=%d totalCost=%d", rk, txID, cost, totalCost)
-	return space + parser.Delimiter + rk, nil
+	rk := hashKey(rb)
+	if err := u.writeRoot(ctx, rk, rb, fc.rawRootSize(rb), existing != nil || ret.dedup()); err != nil {
+		return "", err
+	}
+	m.Path = space + parser.Delimiter + rk
+	return m.Path, ret.saveManifest(m)
 }
 
-// TODO: make multi-threaded
-func Download(ctx context.Context, cli client.Client, path string, f io.Writer) error {
-	exists, rb, _, err := cli.Resolve(ctx, path)
+// maxChildren returns the most children of each [Node] with at most
+// [chunkSize] bytes.
+func (op *Op) maxChildren(chunkSize int) int {
+	n := maxChildren(chunkSize)
+	if op.fanout < 2 || op.fanout > n {
+		return n
+	}
+	return op.fanout
+}
+
+// dedup returns true if chunks and index nodes that already exist in the
+// space are not issued again (see [WithContentDefinedChunking]).
+func (op *Op) dedup() bool {
+	return op.avgChunkSize > 0 && len(op.recipients) == 0
+}
+
+// loadManifest returns the [Manifest] of an [Upload] and, if the upload is
+// resumed, which of its chunks already exist.
+func (op *Op) loadManifest(
+	ctx context.Context, cli client.Client, space string, chunkSize int,
+) (*Manifest, map[string]bool, error) {
+	m := &Manifest{
+		Space:        space,
+		ChunkSize:    chunkSize,
+		AvgChunkSize: op.avgChunkSize,
+		Compression:  op.compression,
+	}
+	if len(op.manifest) == 0 {
+		return m, nil, nil
+	}
+	prev, err := LoadManifest(op.manifest)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return m, nil, nil
+	case err != nil:
+		return nil, nil, err
+	case prev.Space != space || prev.ChunkSize != chunkSize || prev.AvgChunkSize != op.avgChunkSize ||
+		prev.Compression != op.compression:
+		return nil, nil, fmt.Errorf(
+			"%w: manifest is for space %s with chunk size %d (average %d) and compression %q",
+			ErrManifestMismatch, prev.Space, prev.ChunkSize, prev.AvgChunkSize, prev.Compression,
+		)
+	case (len(prev.Key) > 0) != (len(op.recipients) > 0):
+		return nil, nil, fmt.Errorf("%w: manifest encryption %t", ErrManifestMismatch, len(prev.Key) > 0)
+	}
+	existing, err := prev.existing(ctx, cli)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
-	if !exists {
-		return fmt.Errorf("%w:%s", ErrMissing, path)
+	return prev, existing, nil
+}
+
+// privateKey returns the key loaded by [WithDecryptionKey].
+func (op *Op) privateKey() (*ecdsa.PrivateKey, error) {
+	if op.priv != nil {
+		return op.priv, nil
 	}
-	var r Root
-	if err := json.Unmarshal(rb, &r); err != nil {
-		return err
+	if op.loadKey == nil {
+		return nil, ErrEncrypted
+	}
+	priv, err := op.loadKey()
+	if err != nil {
+		return nil, err
 	}
+	op.priv = priv
+	return priv, nil
+}
 
-	// Use small file optimization
-	if contentLen := len(r.Contents); contentLen > 0 {
-		if _, err := f.Write(r.Contents); err != nil {
-			return err
-		}
-		color.Yellow("downloaded path=%s size=%fKB", path, float64(contentLen)/units.KiB)
+func (op *Op) saveManifest(m *Manifest) error {
+	if len(op.manifest) == 0 {
 		return nil
 	}
+	return m.Save(op.manifest)
+}
+
+// Download writes the file at [path] to [f]. Every node of the Merkle DAG of
+// the file is verified against its key as it is fetched, index nodes are
+// fetched as they are reached, and chunks are fetched concurrently (see
+// [WithWorkers]) and written in order. If the root has a [FileMeta.SHA256],
+// the hash of the written file is compared to it once the whole file is
+// written. Encrypted files are decrypted with the key of [WithDecryptionKey].
+func Download(ctx context.Context, cli client.Client, path string, f io.Writer, opts ...OpOption) error {
+	ret := &Op{workers: DefaultWorkers, retries: DefaultRetries}
+	ret.applyOpts(opts)
 
-	if len(r.Children) == 0 {
+	space, r, err := resolveRoot(ctx, cli, path)
+	if err != nil {
+		return err
+	}
+	if len(r.Contents) == 0 && len(r.Children) == 0 {
 		return ErrEmpty
 	}
+	d := &downloader{
+		op:      ret,
+		clients: append([]client.Client{cli}, ret.clients...),
+		space:   space,
+	}
+	return d.file(ctx, path, r, f)
+}
 
-	// Path must be formatted correctly if made it here
-	space := strings.Split(path, parser.Delimiter)[0]
+// Stat returns the verified root [Node] of the file at [path] (including its
+// [FileMeta], if any). The [FileMeta] of an encrypted file is only populated
+// if it can be decrypted (see [WithDecryptionKey]).
+func Stat(ctx context.Context, cli client.Client, path string, opts ...OpOption) (*Node, error) {
+	ret := &Op{}
+	ret.applyOpts(opts)
 
-	amountDownloaded := 0
-	for _, h := range r.Children {
-		chunk := space + parser.Delimiter + h
-		exists, b, _, err := cli.Resolve(ctx, chunk)
-		if err != nil {
-			return err
-		}
-		if !exists {
-			return fmt.Errorf("%w:%s", ErrMissing, chunk)
-		}
-		if _, err := f.Write(b); err != nil {
-			return err
-		}
-		size := len(b)
-		color.Yellow("downloaded chunk=%s size=%fKB", chunk, float64(size)/units.KiB)
-		amountDownloaded += size
+	_, r, err := resolveRoot(ctx, cli, path)
+	if err != nil || r.Encryption == nil || ret.loadKey == nil {
+		return r, err
 	}
-	color.Yellow("download path=%s size=%fMB", path, float64(amountDownloaded)/units.MiB)
-	return nil
+	priv, err := ret.privateKey()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := openRoot(r, priv); err != nil {
+		return nil, err
+	}
+	return r, nil
 }
 
-// Delete all hashes under a root
-func Delete(ctx context.Context, cli client.Client, path string, priv *ecdsa.PrivateKey) error {
+// resolveRoot returns the space and the verified root [Node] of the file at
+// [path].
+func resolveRoot(ctx context.Context, cli client.Client, path string) (string, *Node, error) {
 	exists, rb, _, err := cli.Resolve(ctx, path)
 	if err != nil {
-		return err
+		return "", nil, err
 	}
 	if !exists {
-		return fmt.Errorf("%w:%s", ErrMissing, path)
-	}
-	var r Root
-	if err := json.Unmarshal(rb, &r); err != nil {
-		return err
+		return "", nil, fmt.Errorf("%w:%s", ErrMissing, path)
 	}
 	// Path must be formatted correctly if made it here
 	spl := strings.Split(path, parser.Delimiter)
-	space := spl[0]
-	root := spl[1]
-	opts := []client.OpOption{client.WithPollTx()}
-	totalCost := uint64(0)
-	deleted := map[string]struct{}{}
-	for _, h := range r.Children {
-		if _, ok := deleted[h]; ok {
-			color.Yellow("already deleted k=%s, skipping", h)
+	r, err := parseNode(spl[1], rb)
+	if err != nil {
+		return "", nil, err
+	}
+	return spl[0], r, nil
+}
+
+// Delete all nodes and chunks of the file at [path]
+//
+// Nodes and chunks can be shared with other files (like the files of an
+// [UploadDir], or versions uploaded [WithContentDefinedChunking]), so every
+// value stored at its hash is fetched first ([WithWorkers] at a time) and
+// keys that are reachable from the root of another file or directory are
+// skipped. Files that are reachable from another root (like the entries of a
+// [Dir]) return [ErrShared].
+func Delete(ctx context.Context, cli client.Client, path string, signer client.Signer, opts ...OpOption) error {
+	ret := &Op{workers: DefaultWorkers}
+	ret.applyOpts(opts)
+
+	space, r, err := resolveRoot(ctx, cli, path)
+	if err != nil {
+		return err
+	}
+	rootKey := strings.Split(path, parser.Delimiter)[1]
+	_, values, err := cli.Info(ctx, space)
+	if err != nil {
+		return err
+	}
+	g := newGraph(ret, cli, space)
+	if err := g.walk(ctx, values); err != nil {
+		return err
+	}
+	roots := []string{}
+	for _, k := range g.unreferenced() {
+		if k != rootKey {
+			roots = append(roots, k)
+		}
+	}
+	shared := g.reachable(roots)
+	if _, ok := shared[rootKey]; ok {
+		return fmt.Errorf("%w: %s", ErrShared, path)
+	}
+	d := &deleter{
+		op:      ret,
+		cli:     cli,
+		signer:  signer,
+		space:   space,
+		txOpts:  append([]client.OpOption{client.WithPollTx()}, ret.txOpts...),
+		shared:  shared,
+		deleted: map[string]struct{}{},
+	}
+	if err := d.node(ctx, r); err != nil {
+		return err
+	}
+	return d.delete(ctx, rootKey, &Event{Root: true})
+}
+
+// deleter deletes the chunks under a [Node] before deleting the node itself
+// (so that an interrupted [Delete] can be retried).
+type deleter struct {
+	op     *Op
+	cli    client.Client
+	signer client.Signer
+	space  string
+	txOpts []client.OpOption
+
+	totalCost uint64
+	// Keys reachable from other roots
+	shared  map[string]struct{}
+	deleted map[string]struct{}
+}
+
+func (d *deleter) node(ctx context.Context, n *Node) error {
+	for _, k := range n.Children {
+		if _, ok := d.shared[k]; ok {
+			d.op.report(&Event{Op: EventSkip, Key: k, Index: n.Height > 0})
 			continue
 		}
-		tx := &chain.DeleteTx{
-			BaseTx: &chain.BaseTx{},
-			Space:  space,
-			Key:    h,
+		_, deleted := d.deleted[k]
+		if n.Height > 0 && !deleted {
+			exists, b, _, err := d.cli.Resolve(ctx, d.space+parser.Delimiter+k)
+			if err != nil {
+				return err
+			}
+			if !exists {
+				return fmt.Errorf("%w:%s", ErrMissing, k)
+			}
+			child, err := parseNode(k, b)
+			if err != nil {
+				return err
+			}
+			if err := d.node(ctx, child); err != nil {
+				return err
+			}
 		}
-		txID, cost, err := client.SignIssueRawTx(ctx, cli, tx, priv, opts...)
-		if err != nil {
+		if err := d.delete(ctx, k, &Event{Index: n.Height > 0}); err != nil {
 			return err
 		}
-		totalCost += cost
-		color.Yellow("deleted k=%s txID=%s cost=%d totalCost=%d", h, txID, cost, totalCost)
-		deleted[h] = struct{}{}
+	}
+	return nil
+}
+
+// delete issues a [chain.DeleteTx] for [key] (unless it was already deleted)
+// and reports [e].
+func (d *deleter) delete(ctx context.Context, key string, e *Event) error {
+	e.Key = key
+	if _, ok := d.deleted[key]; ok {
+		e.Op = EventSkip
+		d.op.report(e)
+		return nil
 	}
 	tx := &chain.DeleteTx{
 		BaseTx: &chain.BaseTx{},
-		Space:  space,
-		Key:    root,
+		Space:  d.space,
+		Key:    key,
 	}
-	txID, cost, err := client.SignIssueRawTx(ctx, cli, tx, priv, opts...)
+	txID, cost, err := client.SignIssueRawTx(ctx, d.cli, tx, d.signer, d.txOpts...)
 	if err != nil {
 		return err
 	}
-	totalCost += cost
-	color.Yellow("deleted root=%s txID=%s cost=%d totalCost=%d", path, txID, cost, totalCost)
+	d.totalCost += cost
+	e.Op, e.TxID, e.Cost, e.TotalCost = EventDelete, txID, cost, d.totalCost
+	d.op.report(e)
+	d.deleted[key] = struct{}{}
 	return nil
 }
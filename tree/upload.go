@@ -0,0 +1,678 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package tree
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/ava-labs/spacesvm/chain"
+	"github.com/ava-labs/spacesvm/client"
+)
+
+const (
+	pollInterval = time.Second
+
+	// sniffLen is the most bytes used to detect the content type of a file
+	sniffLen = 512
+
+	// maxBatchSize is the most bytes of chunks [addFile] holds while checking
+	// which of them already exist in the space
+	maxBatchSize = 32 * 1024 * 1024
+)
+
+// uploader issues chunk [chain.SetTx]s without waiting for each to be
+// confirmed. New chunks are issued while the load units of unconfirmed
+// chunks are below [budget] and chunks that are not confirmed within the
+// lookback window (when their block ID is no longer accepted) are re-issued.
+type uploader struct {
+	op     *Op
+	cli    client.Client
+	signer client.Signer
+	space  string
+
+	rules    *chain.Genesis
+	budget   uint64
+	lookback time.Duration
+
+	// Chunks waiting to be (re-)issued
+	queue []*pendingChunk
+	// Chunks issued but not confirmed yet
+	inflight      []*pendingChunk
+	inflightUnits uint64
+
+	totalCost uint64
+	// Cost saved by compression
+	totalSaved uint64
+
+	// Chunks and nodes added so far (by key), to only issue each once
+	uploaded map[string]*ManifestChunk
+	nodes    map[string]struct{}
+	// Chunks that were found in the space instead of being issued
+	reused []string
+
+	// Called after chunks are confirmed
+	checkpoint func() error
+}
+
+type pendingChunk struct {
+	key   string
+	value []byte
+	// Size of [value] without compression
+	raw   int
+	units uint64
+	// Set for index [Node]s
+	index bool
+	// Set for chunks of the file if there is a [Manifest]
+	entry *ManifestChunk
+
+	txID   ids.ID
+	cost   uint64
+	issued time.Time
+}
+
+func newUploader(
+	ctx context.Context, op *Op, cli client.Client, signer client.Signer, space string,
+) (*uploader, error) {
+	g, err := cli.Rules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	budget := op.budget
+	if budget == 0 {
+		budget = DefaultUploadBlocks * g.TargetBlockSize
+	}
+	return &uploader{
+		op:       op,
+		cli:      cli,
+		signer:   signer,
+		space:    space,
+		rules:    g,
+		budget:   budget,
+		lookback: time.Duration(g.LookbackWindow) * time.Second,
+		uploaded: map[string]*ManifestChunk{},
+		nodes:    map[string]struct{}{},
+	}, nil
+}
+
+// fileChunks describes a file added by [addFile].
+type fileChunks struct {
+	// Keys and sizes of the chunks of the file
+	keys  []string
+	sizes []uint64
+	// Contents of the file if it fits in a single chunk
+	contents []byte
+	// Size of the file
+	size uint64
+	// Keccak256 hash of the file
+	hash string
+	// SHA-256 hash of the file
+	sha256 string
+	// Start of the file (used to detect its content type)
+	head []byte
+
+	// Populated if the file is encrypted
+	key    []byte
+	cipher *fileCipher
+	// [Node.Compression] of the file
+	compression string
+}
+
+// addFile adds the chunks of [f] and records them in [m]. Chunks that were
+// already added (by any file), that are in [existing], or that already exist
+// in the space (see [Op.dedup]) are skipped. If the upload is encrypted, the
+// key of the file is [m.Key] (which is generated if it is not set).
+func (u *uploader) addFile(
+	ctx context.Context, f io.Reader, chunkSize int, m *Manifest, existing map[string]bool,
+) (*fileChunks, error) {
+	fc := &fileChunks{compression: u.op.compression}
+	if len(u.op.recipients) > 0 {
+		if len(m.Key) == 0 {
+			key, err := client.NewKey()
+			if err != nil {
+				return nil, err
+			}
+			m.Key = key
+		}
+		c, err := newFileCipher(m.Key)
+		if err != nil {
+			return nil, err
+		}
+		fc.key, fc.cipher = m.Key, c
+	}
+	// Encoded chunks must still fit in [chunkSize]
+	readSize := chunkSize - fc.cipher.overhead() - compressOverhead(fc.compression, chunkSize)
+	chunks := newChunker(f, readSize, u.op.avgChunkSize)
+	fileHash := crypto.NewKeccakState()
+	fileSHA := sha256.New()
+	b := &chunkBatch{}
+	for {
+		chunk, last, err := chunks.next()
+		if err != nil {
+			return nil, err
+		}
+		if len(chunk) == 0 {
+			break
+		}
+		if _, err := fileHash.Write(chunk); err != nil {
+			return nil, err
+		}
+		if _, err := fileSHA.Write(chunk); err != nil {
+			return nil, err
+		}
+		if fc.head == nil {
+			n := len(chunk)
+			if n > sniffLen {
+				n = sniffLen
+			}
+			fc.head = append([]byte{}, chunk[:n]...)
+		}
+		fc.size += uint64(len(chunk))
+		i := len(fc.keys)
+		value, err := fc.encode(uint64(i), chunk)
+		if err != nil {
+			return nil, err
+		}
+
+		// Use small file optimization (if the encoded contents fit in the
+		// root)
+		if last && i == 0 && base64.StdEncoding.EncodedLen(len(value))+nodeOverhead <= chunkSize {
+			fc.contents = value
+			break
+		}
+		k := hashKey(value)
+		switch {
+		case i == len(m.Chunks):
+			m.Chunks = append(m.Chunks, &ManifestChunk{Key: k})
+		case m.Chunks[i].Key != k:
+			return nil, fmt.Errorf("%w: chunk %d changed", ErrManifestMismatch, i)
+		}
+		// [chunk] is reused for the next chunk
+		b.chunks = append(b.chunks, &batchedChunk{i: i, key: k, value: append([]byte{}, value...), raw: len(chunk)})
+		b.size += len(value)
+		if !u.op.dedup() || len(b.chunks) == hasKeysBatch || b.size >= maxBatchSize {
+			if err := u.addBatch(ctx, b, m, existing); err != nil {
+				return nil, err
+			}
+		}
+		fc.keys = append(fc.keys, k)
+		fc.sizes = append(fc.sizes, uint64(len(chunk)))
+		if last {
+			break
+		}
+	}
+	if err := u.addBatch(ctx, b, m, existing); err != nil {
+		return nil, err
+	}
+	fc.hash = strings.ToLower(common.Bytes2Hex(fileHash.Sum(nil)))
+	fc.sha256 = hex.EncodeToString(fileSHA.Sum(nil))
+	return fc, nil
+}
+
+// chunkBatch holds the chunks read by [addFile] until it is checked which of
+// them already exist in the space.
+type chunkBatch struct {
+	chunks []*batchedChunk
+	// Total size of the values of [chunks]
+	size int
+}
+
+type batchedChunk struct {
+	// Index of the chunk in the file
+	i     int
+	key   string
+	value []byte
+	// Size of [value] without compression or encryption
+	raw int
+}
+
+// addBatch adds the chunks of [b] (in order) and empties it. Which of them
+// already exist in the space is only checked (with a single [hasKeys] call)
+// if [Op.dedup] is set. Other chunks are only skipped if they were added by
+// this upload or exist when it is resumed.
+func (u *uploader) addBatch(ctx context.Context, b *chunkBatch, m *Manifest, existing map[string]bool) error {
+	found := map[string]bool{}
+	if u.op.dedup() {
+		keys := []string{}
+		for _, c := range b.chunks {
+			if _, added := u.uploaded[c.key]; !added && !existing[c.key] {
+				keys = append(keys, c.key)
+			}
+		}
+		var err error
+		if found, err = hasKeys(ctx, u.cli, u.space, keys); err != nil {
+			return err
+		}
+	}
+	for _, c := range b.chunks {
+		entry, added := u.uploaded[c.key]
+		switch {
+		case added:
+			m.Chunks[c.i] = entry
+			u.op.report(&Event{Op: EventSkip, Key: c.key, Size: len(c.value)})
+		case existing[c.key] || found[c.key]:
+			m.Chunks[c.i].Confirmed = true
+			u.uploaded[c.key] = m.Chunks[c.i]
+			u.reused = append(u.reused, c.key)
+			u.op.report(&Event{Op: EventSkip, Key: c.key, Size: len(c.value)})
+		default:
+			m.Chunks[c.i].Confirmed = false
+			if err := u.add(ctx, c.key, c.value, c.raw, m.Chunks[c.i]); err != nil {
+				return err
+			}
+			u.uploaded[c.key] = m.Chunks[c.i]
+		}
+	}
+	b.chunks, b.size = nil, 0
+	return nil
+}
+
+// checkReused returns [ErrMissing] if a chunk that was found in the space
+// (instead of being issued) no longer exists. Reused chunks keep their update
+// time, so [GC] may delete them until a root references them. Uploading again
+// issues the missing chunks.
+func (u *uploader) checkReused(ctx context.Context) error {
+	exists, err := hasKeys(ctx, u.cli, u.space, u.reused)
+	if err != nil {
+		return err
+	}
+	for _, k := range u.reused {
+		if !exists[k] {
+			return fmt.Errorf("%w: reused chunk %s was deleted (upload again)", ErrMissing, k)
+		}
+	}
+	return nil
+}
+
+// encode compresses and encrypts (as configured) chunk [i] of the file.
+func (fc *fileChunks) encode(i uint64, chunk []byte) ([]byte, error) {
+	value := chunk
+	if len(fc.compression) > 0 {
+		var err error
+		value, err = compress(chunk)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if fc.cipher != nil {
+		value = fc.cipher.seal(i, value)
+	}
+	return value, nil
+}
+
+// rawRootSize returns the size the root [b] of the file would have without
+// compression.
+func (fc *fileChunks) rawRootSize(b []byte) int {
+	if len(fc.compression) == 0 || len(fc.contents) == 0 {
+		return len(b)
+	}
+	raw := base64.StdEncoding.EncodedLen(int(fc.size) + fc.cipher.overhead())
+	return len(b) - base64.StdEncoding.EncodedLen(len(fc.contents)) + raw
+}
+
+// meta returns the [FileMeta] of a file added by [addFile] with [name] and
+// modification time [modified] (both optional). The content type is detected from
+// the extension of [name] or the start of the file.
+func (fc *fileChunks) meta(name string, modified int64) *FileMeta {
+	m := &FileMeta{Name: name, SHA256: fc.sha256, Modified: modified}
+	if t := mime.TypeByExtension(filepath.Ext(name)); len(t) > 0 {
+		m.ContentType = t
+	} else if len(fc.head) > 0 {
+		m.ContentType = http.DetectContentType(fc.head)
+	}
+	return m
+}
+
+// fileInfo returns the base name and modification time of [f] if it has
+// Name and Stat methods (like [os.File]). Names that are not valid
+// [FileMeta.Name]s are ignored.
+func fileInfo(f io.Reader) (string, int64) {
+	var (
+		name     string
+		modified int64
+	)
+	if nf, ok := f.(interface{ Name() string }); ok {
+		name = filepath.Base(nf.Name())
+		if checkName(name) != nil {
+			name = ""
+		}
+	}
+	if sf, ok := f.(interface{ Stat() (fs.FileInfo, error) }); ok {
+		if info, err := sf.Stat(); err == nil {
+			modified = info.ModTime().Unix()
+		}
+	}
+	return name, modified
+}
+
+// seal encrypts the [Node.Meta] of the root [r] of an encrypted file and
+// wraps its key to [recipients].
+func (fc *fileChunks) seal(r *Node, recipients []*ecdsa.PublicKey) error {
+	if fc.cipher == nil {
+		return nil
+	}
+	return fc.cipher.sealRoot(r, fc.key, recipients)
+}
+
+// marshalRoot encodes the root [r] of a file and ensures it fits in a chunk.
+func marshalRoot(r *Node, chunkSize int) ([]byte, error) {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) > chunkSize {
+		return nil, fmt.Errorf("%w: %d bytes", ErrRootTooLarge, len(b))
+	}
+	return b, nil
+}
+
+// root returns the root [Node] of a file added by [addFile] once all of its
+// chunks are confirmed (issuing any index nodes it needs).
+func (u *uploader) root(ctx context.Context, fc *fileChunks, chunkSize int, resumed bool) (*Node, error) {
+	r := &Node{Version: NodeVersion, Size: fc.size, Contents: fc.contents}
+	if len(fc.keys) > 0 {
+		var err error
+		r, err = u.index(ctx, fc.keys, fc.sizes, u.op.maxChildren(chunkSize), resumed)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(fc.compression) > 0 {
+		r.Version = EncodedNodeVersion
+		r.Compression = fc.compression
+	}
+	return r, nil
+}
+
+// writeRoot issues the root [key] of an upload (which would be [raw] bytes
+// without compression) and waits for it to be confirmed. If [resumed], the
+// root is not issued again if it exists.
+func (u *uploader) writeRoot(ctx context.Context, key string, value []byte, raw int, resumed bool) error {
+	if resumed {
+		exists, err := u.cli.HasKeys(ctx, u.space, []string{key})
+		if err != nil {
+			return err
+		}
+		if exists[0] {
+			u.op.report(&Event{Op: EventSkip, Key: key, Root: true, Size: len(value)})
+			return nil
+		}
+	}
+	tx := &chain.SetTx{
+		BaseTx: &chain.BaseTx{},
+		Space:  u.space,
+		Key:    key,
+		Value:  value,
+	}
+	txOpts := append([]client.OpOption{client.WithPollTx()}, u.op.txOpts...)
+	txID, cost, err := client.SignIssueRawTx(ctx, u.cli, tx, u.signer, txOpts...)
+	if err != nil {
+		return err
+	}
+	u.totalCost += cost
+	saved := u.savedCost(cost, tx.FeeUnits(u.rules), len(value), raw)
+	u.totalSaved += saved
+	u.op.report(&Event{
+		Op: EventUpload, Key: key, Root: true, Size: len(value),
+		TxID: txID, Cost: cost, TotalCost: u.totalCost, Saved: saved, TotalSaved: u.totalSaved,
+	})
+	return nil
+}
+
+// savedCost returns how much less a value of [stored] bytes cost (at the
+// price of [cost] for [units]) than a value of [raw] bytes would have (see
+// [chain.SetTx.FeeUnits]).
+func (u *uploader) savedCost(cost uint64, units uint64, stored int, raw int) uint64 {
+	if raw <= stored || units == 0 {
+		return 0
+	}
+	size := u.rules.ValueUnitSize
+	return (uint64(raw)/size - uint64(stored)/size) * (cost / units)
+}
+
+// add queues a chunk and returns once it has been issued (which may require
+// waiting for earlier chunks to be confirmed).
+func (u *uploader) add(ctx context.Context, key string, value []byte, raw int, entry *ManifestChunk) error {
+	return u.queueChunk(ctx, &pendingChunk{key: key, value: value, raw: raw, entry: entry})
+}
+
+// addNode queues an index [Node] (or [Dir]) like [add] unless it was already
+// added.
+func (u *uploader) addNode(ctx context.Context, key string, value []byte, raw int) error {
+	if _, ok := u.nodes[key]; ok {
+		u.op.report(&Event{Op: EventSkip, Key: key, Index: true, Size: len(value)})
+		return nil
+	}
+	u.nodes[key] = struct{}{}
+	return u.queueChunk(ctx, &pendingChunk{key: key, value: value, raw: raw, index: true})
+}
+
+func (u *uploader) queueChunk(ctx context.Context, c *pendingChunk) error {
+	c.units = u.tx(c).LoadUnits(u.rules)
+	u.queue = append(u.queue, c)
+	return u.run(ctx, false)
+}
+
+// wait returns once all chunks have been confirmed.
+func (u *uploader) wait(ctx context.Context) error {
+	return u.run(ctx, true)
+}
+
+func (u *uploader) run(ctx context.Context, drain bool) error {
+	for {
+		if err := u.issue(ctx); err != nil {
+			return err
+		}
+		if len(u.queue) == 0 && (!drain || len(u.inflight) == 0) {
+			return nil
+		}
+		if err := u.poll(ctx); err != nil {
+			return err
+		}
+	}
+}
+
+func (u *uploader) tx(c *pendingChunk) *chain.SetTx {
+	return &chain.SetTx{
+		BaseTx: &chain.BaseTx{},
+		Space:  u.space,
+		Key:    c.key,
+		Value:  c.value,
+	}
+}
+
+// issue signs and issues (up to [Op.workers] at once) queued chunks while
+// they fit in [budget].
+func (u *uploader) issue(ctx context.Context) error {
+	workers := u.op.workers
+	if workers < 1 {
+		workers = 1
+	}
+	for len(u.queue) > 0 {
+		batch := []*pendingChunk{}
+		units := u.inflightUnits
+		for _, c := range u.queue {
+			if len(batch) == workers || (units > 0 && units+c.units > u.budget) {
+				break
+			}
+			batch = append(batch, c)
+			units += c.units
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+
+		var (
+			wg   sync.WaitGroup
+			errs = make([]error, len(batch))
+		)
+		for i, c := range batch {
+			wg.Add(1)
+			go func(i int, c *pendingChunk) {
+				defer wg.Done()
+				c.txID, c.cost, errs[i] = client.SignIssueRawTx(ctx, u.cli, u.tx(c), u.signer, u.op.txOpts...)
+				c.issued = time.Now()
+			}(i, c)
+		}
+		wg.Wait()
+
+		// Chunks issued alongside a failed one are tracked like any other, so
+		// that they count against [budget] and are not issued again
+		var (
+			failed   []*pendingChunk
+			firstErr error
+		)
+		for i, c := range batch {
+			if errs[i] != nil {
+				failed = append(failed, c)
+				if firstErr == nil {
+					firstErr = errs[i]
+				}
+				continue
+			}
+			if c.entry != nil {
+				c.entry.TxID = c.txID
+			}
+			u.inflight = append(u.inflight, c)
+			u.inflightUnits += c.units
+		}
+		u.queue = append(failed, u.queue[len(batch):]...)
+		if firstErr != nil {
+			return firstErr
+		}
+	}
+	return nil
+}
+
+// poll waits for [pollInterval] and then checks which inflight chunks were
+// confirmed.
+func (u *uploader) poll(ctx context.Context) error {
+	select {
+	case <-time.After(pollInterval):
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	inflight := u.inflight[:0]
+	confirmed := false
+	for _, c := range u.inflight {
+		has, err := u.cli.HasTx(ctx, c.txID)
+		switch {
+		case err != nil:
+			// Transient errors are retried on the next poll
+			inflight = append(inflight, c)
+		case has:
+			confirmed = true
+			if c.entry != nil {
+				c.entry.Confirmed = true
+			}
+			u.inflightUnits -= c.units
+			u.totalCost += c.cost
+			saved := u.savedCost(c.cost, c.units, len(c.value), c.raw)
+			u.totalSaved += saved
+			u.op.report(&Event{
+				Op: EventUpload, Key: c.key, Index: c.index, Size: len(c.value),
+				TxID: c.txID, Cost: c.cost, TotalCost: u.totalCost, Saved: saved, TotalSaved: u.totalSaved,
+			})
+		case time.Since(c.issued) > u.lookback:
+			u.inflightUnits -= c.units
+			u.op.report(&Event{Op: EventReissue, Key: c.key, Index: c.index, Size: len(c.value), TxID: c.txID})
+			u.queue = append([]*pendingChunk{c}, u.queue...)
+		default:
+			inflight = append(inflight, c)
+		}
+	}
+	u.inflight = inflight
+	if !confirmed {
+		return nil
+	}
+	return u.save()
+}
+
+func (u *uploader) save() error {
+	if u.checkpoint == nil {
+		return nil
+	}
+	return u.checkpoint()
+}
+
+// index issues the index [Node]s needed for [keys] (of chunks with [sizes])
+// to fit in a root with at most [fanout] children and returns the root. Each
+// level is confirmed before the next one is issued. If [resumed], nodes that
+// already exist are not issued again.
+func (u *uploader) index(
+	ctx context.Context, keys []string, sizes []uint64, fanout int, resumed bool,
+) (*Node, error) {
+	height := uint64(0)
+	for len(keys) > fanout {
+		var (
+			nextKeys  []string
+			nextSizes []uint64
+			values    [][]byte
+		)
+		for start := 0; start < len(keys); start += fanout {
+			end := start + fanout
+			if end > len(keys) {
+				end = len(keys)
+			}
+			n := &Node{Version: NodeVersion, Height: height, Children: keys[start:end]}
+			for _, size := range sizes[start:end] {
+				n.Size += size
+			}
+			b, err := json.Marshal(n)
+			if err != nil {
+				return nil, err
+			}
+			nextKeys = append(nextKeys, hashKey(b))
+			nextSizes = append(nextSizes, n.Size)
+			values = append(values, b)
+		}
+
+		var existing map[string]bool
+		if resumed {
+			var err error
+			existing, err = hasKeys(ctx, u.cli, u.space, nextKeys)
+			if err != nil {
+				return nil, err
+			}
+		}
+		for i, k := range nextKeys {
+			if existing[k] {
+				u.op.report(&Event{Op: EventSkip, Key: k, Index: true, Size: len(values[i])})
+				continue
+			}
+			if err := u.addNode(ctx, k, values[i], len(values[i])); err != nil {
+				return nil, err
+			}
+		}
+		if err := u.wait(ctx); err != nil {
+			return nil, err
+		}
+		keys, sizes = nextKeys, nextSizes
+		height++
+	}
+
+	root := &Node{Version: NodeVersion, Height: height, Children: keys}
+	for _, size := range sizes {
+		root.Size += size
+	}
+	return root, nil
+}
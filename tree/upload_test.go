@@ -0,0 +1,312 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package tree
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/ava-labs/spacesvm/chain"
+	"github.com/ava-labs/spacesvm/client"
+)
+
+var errIssue = errors.New("issue failed")
+
+// uploadClient accepts every [chain.SetTx] and confirms it on the first
+// [client.Client.HasTx] unless the key is in [fail] or [drop].
+type uploadClient struct {
+	client.Client
+
+	g *chain.Genesis
+
+	mu     sync.Mutex
+	txs    map[ids.ID]string
+	issued map[string]int
+	// Number of [client.Client.HasKeys] calls
+	hasKeys int
+	// Keys whose next issue fails
+	fail map[string]bool
+	// Keys whose next tx is never confirmed
+	drop    map[string]bool
+	dropped map[ids.ID]bool
+}
+
+func newUploadClient() *uploadClient {
+	g := chain.DefaultGenesis()
+	g.Magic = 1
+	// Re-issue unconfirmed chunks quickly
+	g.LookbackWindow = 1
+	return &uploadClient{
+		g:       g,
+		txs:     map[ids.ID]string{},
+		issued:  map[string]int{},
+		fail:    map[string]bool{},
+		drop:    map[string]bool{},
+		dropped: map[ids.ID]bool{},
+	}
+}
+
+func (c *uploadClient) Rules(context.Context) (*chain.Genesis, error) { return c.g, nil }
+
+func (c *uploadClient) Accepted(context.Context) (ids.ID, error) { return ids.GenerateTestID(), nil }
+
+func (c *uploadClient) SuggestedRawFee(context.Context) (uint64, uint64, error) { return 1, 0, nil }
+
+func (c *uploadClient) IssueRawTx(_ context.Context, d []byte) (ids.ID, error) {
+	tx := new(chain.Transaction)
+	if _, err := chain.Unmarshal(d, tx); err != nil {
+		return ids.Empty, err
+	}
+	key := tx.UnsignedTransaction.(*chain.SetTx).Key
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.fail[key] {
+		delete(c.fail, key)
+		return ids.Empty, fmt.Errorf("%w: %s", errIssue, key)
+	}
+	txID := ids.GenerateTestID()
+	c.txs[txID] = key
+	c.issued[key]++
+	if c.drop[key] {
+		delete(c.drop, key)
+		c.dropped[txID] = true
+	}
+	return txID, nil
+}
+
+func (c *uploadClient) HasTx(_ context.Context, txID ids.ID) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return !c.dropped[txID], nil
+}
+
+func (c *uploadClient) HasKeys(_ context.Context, _ string, keys []string) ([]bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.hasKeys++
+	exists := make([]bool, len(keys))
+	for i, k := range keys {
+		exists[i] = c.issued[k] > 0
+	}
+	return exists, nil
+}
+
+func newTestUploader(t *testing.T, cli *uploadClient, op *Op) *uploader {
+	t.Helper()
+
+	priv, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := newUploader(context.Background(), op, cli, client.NewKeySigner(priv), "space")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u
+}
+
+func testChunk(u *uploader, i int) *pendingChunk {
+	value := []byte(fmt.Sprintf("chunk %d", i))
+	c := &pendingChunk{key: hashKey(value), value: value, raw: len(value), entry: &ManifestChunk{}}
+	c.units = u.tx(c).LoadUnits(u.rules)
+	return c
+}
+
+func TestUploaderBudget(t *testing.T) {
+	cli := newUploadClient()
+	u := newTestUploader(t, cli, &Op{workers: 4})
+	chunks := make([]*pendingChunk, 5)
+	for i := range chunks {
+		chunks[i] = testChunk(u, i)
+	}
+	// Room for 2 chunks at a time
+	u.budget = 2 * chunks[0].units
+
+	for _, c := range chunks {
+		if err := u.queueChunk(context.Background(), c); err != nil {
+			t.Fatal(err)
+		}
+		if u.inflightUnits > u.budget || len(u.inflight) > 2 {
+			t.Fatalf("%d chunks (%d units) inflight exceed budget %d", len(u.inflight), u.inflightUnits, u.budget)
+		}
+	}
+	if err := u.wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range chunks {
+		if n := cli.issued[c.key]; n != 1 {
+			t.Fatalf("chunk %s issued %d times", c.key, n)
+		}
+		if !c.entry.Confirmed {
+			t.Fatalf("chunk %s not confirmed", c.key)
+		}
+	}
+	if u.inflightUnits != 0 {
+		t.Fatalf("expected no inflight units, got %d", u.inflightUnits)
+	}
+}
+
+func TestUploaderIssueError(t *testing.T) {
+	cli := newUploadClient()
+	u := newTestUploader(t, cli, &Op{workers: 3})
+	chunks := []*pendingChunk{testChunk(u, 0), testChunk(u, 1), testChunk(u, 2)}
+	cli.fail[chunks[1].key] = true
+
+	u.queue = append(u.queue, chunks...)
+	if err := u.issue(context.Background()); !errors.Is(err, errIssue) {
+		t.Fatalf("expected %v, got %v", errIssue, err)
+	}
+	// Siblings of the failed chunk were issued and must be tracked
+	if len(u.inflight) != 2 || u.inflight[0] != chunks[0] || u.inflight[1] != chunks[2] {
+		t.Fatalf("unexpected inflight chunks %v", u.inflight)
+	}
+	if u.inflightUnits != chunks[0].units+chunks[2].units {
+		t.Fatalf("unexpected inflight units %d", u.inflightUnits)
+	}
+	if chunks[0].entry.TxID == ids.Empty || chunks[2].entry.TxID == ids.Empty {
+		t.Fatal("manifest entries of issued chunks have no txID")
+	}
+	if len(u.queue) != 1 || u.queue[0] != chunks[1] {
+		t.Fatalf("unexpected queued chunks %v", u.queue)
+	}
+
+	if err := u.wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range chunks {
+		if n := cli.issued[c.key]; n != 1 {
+			t.Fatalf("chunk %s issued %d times", c.key, n)
+		}
+	}
+}
+
+func TestUploaderReissue(t *testing.T) {
+	cli := newUploadClient()
+	var reissued []string
+	u := newTestUploader(t, cli, &Op{
+		workers: 1,
+		progress: func(e *Event) {
+			if e.Op == EventReissue {
+				reissued = append(reissued, e.Key)
+			}
+		},
+	})
+	c := testChunk(u, 0)
+	cli.drop[c.key] = true
+
+	if err := u.queueChunk(context.Background(), c); err != nil {
+		t.Fatal(err)
+	}
+	if err := u.wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := cli.issued[c.key]; n != 2 {
+		t.Fatalf("chunk issued %d times, expected 2", n)
+	}
+	if len(reissued) != 1 || reissued[0] != c.key {
+		t.Fatalf("unexpected reissue events %v", reissued)
+	}
+	if !c.entry.Confirmed || u.inflightUnits != 0 {
+		t.Fatalf("chunk not confirmed (inflight units %d)", u.inflightUnits)
+	}
+}
+
+func TestAddFileDedupBatch(t *testing.T) {
+	f := make([]byte, 64*1024)
+	if _, err := rand.Read(f); err != nil {
+		t.Fatal(err)
+	}
+	cli := newUploadClient()
+	upload := func() (*fileChunks, int) {
+		skipped := 0
+		u := newTestUploader(t, cli, &Op{
+			workers:      16,
+			avgChunkSize: minAverageChunkSize,
+			progress: func(e *Event) {
+				if e.Op == EventSkip {
+					skipped++
+				}
+			},
+		})
+		fc, err := u.addFile(context.Background(), bytes.NewReader(f), 4096, &Manifest{}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := u.wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		return fc, skipped
+	}
+
+	// Existence of all chunks is checked at once
+	fc, skipped := upload()
+	if len(fc.keys) < 2 || len(fc.keys) > hasKeysBatch {
+		t.Fatalf("unexpected number of chunks %d", len(fc.keys))
+	}
+	if cli.hasKeys != 1 {
+		t.Fatalf("expected 1 HasKeys call, got %d", cli.hasKeys)
+	}
+	unique := map[string]struct{}{}
+	for _, k := range fc.keys {
+		unique[k] = struct{}{}
+	}
+	if len(cli.issued) != len(unique) || skipped != len(fc.keys)-len(unique) {
+		t.Fatalf("issued %d and skipped %d of %d chunks", len(cli.issued), skipped, len(fc.keys))
+	}
+
+	// Chunks that exist are not issued again
+	fc, skipped = upload()
+	if cli.hasKeys != 2 {
+		t.Fatalf("expected 2 HasKeys calls, got %d", cli.hasKeys)
+	}
+	if skipped != len(fc.keys) {
+		t.Fatalf("skipped %d of %d chunks", skipped, len(fc.keys))
+	}
+	for k, n := range cli.issued {
+		if n != 1 {
+			t.Fatalf("chunk %s issued %d times", k, n)
+		}
+	}
+}
+
+func TestCheckReused(t *testing.T) {
+	cli := newUploadClient()
+	u := newTestUploader(t, cli, &Op{workers: 1, avgChunkSize: minAverageChunkSize})
+	c := testChunk(u, 0)
+	if err := u.queueChunk(context.Background(), c); err != nil {
+		t.Fatal(err)
+	}
+	if err := u.wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// The chunk is found instead of being issued again
+	u = newTestUploader(t, cli, &Op{workers: 1, avgChunkSize: minAverageChunkSize})
+	m := &Manifest{Chunks: []*ManifestChunk{{Key: c.key}}}
+	b := &chunkBatch{chunks: []*batchedChunk{{key: c.key, value: c.value, raw: c.raw}}}
+	if err := u.addBatch(context.Background(), b, m, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(u.reused) != 1 || cli.issued[c.key] != 1 {
+		t.Fatalf("chunk was not reused (issued %d times)", cli.issued[c.key])
+	}
+	if err := u.checkReused(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Deleted (ex: by [GC]) before the root is written
+	delete(cli.issued, c.key)
+	if err := u.checkReused(context.Background()); !errors.Is(err, ErrMissing) {
+		t.Fatalf("expected %v, got %v", ErrMissing, err)
+	}
+}
@@ -0,0 +1,193 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package vm
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ethereum/go-ethereum/common"
+	log "github.com/inconshreveable/log15"
+
+	"github.com/ava-labs/spacesvm/chain"
+)
+
+const (
+	TimeBuilderName   = "time"
+	ManualBuilderName = "manual"
+)
+
+// AdminService exposes operator-only controls. It is only registered if
+// [AdminAPIEnabled] is set and every request must include [AdminAPIToken].
+type AdminService struct {
+	vm *VM
+}
+
+func (svc *AdminService) authorize(r *http.Request) error {
+	token := svc.vm.config.AdminAPIToken
+	if len(token) == 0 {
+		return ErrMissingAdminToken
+	}
+	provided := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+		return ErrUnauthorized
+	}
+	return nil
+}
+
+type SuccessReply struct {
+	Success bool `serialize:"true" json:"success"`
+}
+
+type MempoolTx struct {
+	TxID   ids.ID         `serialize:"true" json:"txId"`
+	Type   string         `serialize:"true" json:"type"`
+	Sender common.Address `serialize:"true" json:"sender"`
+	Price  uint64         `serialize:"true" json:"price"`
+	Size   uint64         `serialize:"true" json:"size"`
+}
+
+type MempoolReply struct {
+	Txs []*MempoolTx `serialize:"true" json:"txs"`
+}
+
+// Mempool returns all pending txs ordered by price (highest first).
+func (svc *AdminService) Mempool(r *http.Request, _ *struct{}, reply *MempoolReply) error {
+	if err := svc.authorize(r); err != nil {
+		return err
+	}
+	txs := svc.vm.mempool.Txs()
+	sort.Slice(txs, func(i, j int) bool {
+		return txs[i].GetPrice() > txs[j].GetPrice()
+	})
+	reply.Txs = make([]*MempoolTx, len(txs))
+	for i, tx := range txs {
+		reply.Txs[i] = &MempoolTx{
+			TxID:   tx.ID(),
+			Type:   tx.UnsignedTransaction.Activity().Typ,
+			Sender: tx.Sender(),
+			Price:  tx.GetPrice(),
+			Size:   tx.Size(),
+		}
+	}
+	return nil
+}
+
+type DropTxArgs struct {
+	TxID ids.ID `serialize:"true" json:"txId"`
+}
+
+type DropTxReply struct {
+	Dropped bool `serialize:"true" json:"dropped"`
+}
+
+func (svc *AdminService) DropTx(r *http.Request, args *DropTxArgs, reply *DropTxReply) error {
+	if err := svc.authorize(r); err != nil {
+		return err
+	}
+	reply.Dropped = svc.vm.mempool.Remove(args.TxID) != nil
+	log.Info("admin dropped tx", "txID", args.TxID, "found", reply.Dropped)
+	return nil
+}
+
+type SetLogLevelArgs struct {
+	Level string `serialize:"true" json:"level"`
+}
+
+func (svc *AdminService) SetLogLevel(r *http.Request, args *SetLogLevelArgs, reply *SuccessReply) error {
+	if err := svc.authorize(r); err != nil {
+		return err
+	}
+	lvl, err := log.LvlFromString(args.Level)
+	if err != nil {
+		return err
+	}
+	// Only filters what the original handler (and its format) would log, so
+	// levels below its own threshold still cannot be enabled
+	log.Root().SetHandler(log.LvlFilterHandler(lvl, svc.vm.logHandler))
+	log.Info("admin set log level", "level", lvl)
+	reply.Success = true
+	return nil
+}
+
+type PruneReply struct {
+	More bool `serialize:"true" json:"more"`
+}
+
+// Prune runs a single pruning pass. [More] is true if the pass hit
+// [PruneLimit] and there may be more spaces to prune.
+func (svc *AdminService) Prune(r *http.Request, _ *struct{}, reply *PruneReply) error {
+	if err := svc.authorize(r); err != nil {
+		return err
+	}
+	reply.More = svc.vm.pruneCall()
+
+	svc.vm.ctx.Lock.Lock()
+	defer svc.vm.ctx.Lock.Unlock()
+	return svc.vm.pruneErr
+}
+
+// Compact compacts every range in [chain.CompactRanges].
+func (svc *AdminService) Compact(r *http.Request, _ *struct{}, reply *SuccessReply) error {
+	if err := svc.authorize(r); err != nil {
+		return err
+	}
+	for _, cr := range chain.CompactRanges {
+		svc.vm.compactCall(cr)
+	}
+
+	svc.vm.ctx.Lock.Lock()
+	defer svc.vm.ctx.Lock.Unlock()
+	if err := svc.vm.compactErr(); err != nil {
+		return err
+	}
+	reply.Success = true
+	return nil
+}
+
+type SetBlockBuilderArgs struct {
+	Builder string `serialize:"true" json:"builder"`
+}
+
+func (svc *AdminService) SetBlockBuilder(r *http.Request, args *SetBlockBuilderArgs, reply *SuccessReply) error {
+	if err := svc.authorize(r); err != nil {
+		return err
+	}
+	var b func() BlockBuilder
+	switch args.Builder {
+	case TimeBuilderName:
+		b = func() BlockBuilder { return svc.vm.NewTimeBuilder() }
+	case ManualBuilderName:
+		b = func() BlockBuilder { return svc.vm.NewManualBuilder() }
+	default:
+		return fmt.Errorf("%w: %s", ErrUnknownBuilder, args.Builder)
+	}
+
+	// Prevent [BuildBlock] from using the builder while it is swapped
+	svc.vm.ctx.Lock.Lock()
+	defer svc.vm.ctx.Lock.Unlock()
+	svc.vm.SetBlockBuilder(b)
+	log.Info("admin set block builder", "builder", args.Builder)
+	reply.Success = true
+	return nil
+}
+
+type KeyCountsReply struct {
+	Counts map[string]uint64 `serialize:"true" json:"counts"`
+}
+
+// KeyCounts iterates over the entire database and may be slow on large nodes.
+func (svc *AdminService) KeyCounts(r *http.Request, _ *struct{}, reply *KeyCountsReply) (err error) {
+	if err := svc.authorize(r); err != nil {
+		return err
+	}
+	svc.vm.ctx.Lock.Lock()
+	defer svc.vm.ctx.Lock.Unlock()
+	reply.Counts, err = chain.KeyCounts(svc.vm.db)
+	return err
+}
@@ -167,6 +167,11 @@ func (b *TimeBuilder) Build() {
 	log.Debug("starting build loops")
 	defer close(b.doneBuild)
 
+	// The timer must be running for [HandleGenerateBlock] to retry building
+	// when transactions are left in the mempool
+	go b.buildBlockTimer.Dispatch()
+	defer b.buildBlockTimer.Stop()
+
 	for {
 		select {
 		case <-b.vm.mempool.Pending:
@@ -193,7 +198,7 @@ func (b *TimeBuilder) Gossip() {
 	for {
 		select {
 		case <-g.C:
-			newTxs := b.vm.mempool.NewTxs(b.vm.genesis.TargetBlockSize)
+			newTxs := b.vm.mempool.NewTxs(b.vm.Rules(time.Now().Unix()).TargetBlockSize)
 			_ = b.vm.network.GossipNewTxs(newTxs) // handles case where there are none
 		case <-rg.C:
 			_ = b.vm.network.RegossipTxs()
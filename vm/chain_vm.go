@@ -16,6 +16,10 @@ func (vm *VM) Genesis() *chain.Genesis {
 	return vm.genesis
 }
 
+func (vm *VM) Rules(currentTime int64) *chain.Genesis {
+	return vm.upgrades.Rules(vm.genesis, currentTime)
+}
+
 func (vm *VM) IsBootstrapped() bool {
 	return vm.bootstrapped.Get()
 }
@@ -28,6 +32,10 @@ func (vm *VM) Mempool() chain.Mempool {
 	return vm.mempool
 }
 
+func (vm *VM) Metrics() *chain.Metrics {
+	return vm.chainMetrics
+}
+
 func (vm *VM) Verified(b *chain.StatelessBlock) {
 	vm.verifiedBlocks[b.ID()] = b
 	for _, tx := range b.Txs {
@@ -67,7 +75,7 @@ func (vm *VM) Accepted(b *chain.StatelessBlock) {
 }
 
 func (vm *VM) ExecutionContext(currTime int64, lastBlock *chain.StatelessBlock) (*chain.Context, error) {
-	g := vm.genesis
+	g := vm.Rules(currTime)
 	recentBlockIDs := set.Set[ids.ID]{}
 	recentTxIDs := set.Set[ids.ID]{}
 	recentUnits := uint64(0)
@@ -105,10 +113,12 @@ func (vm *VM) ExecutionContext(currTime int64, lastBlock *chain.StatelessBlock)
 	}
 
 	// compute new min price
+	targetUnitsPerSecond := g.TargetBlockSize / uint64(g.TargetBlockRate)
+	targetRangeUnits := targetUnitsPerSecond * uint64(g.LookbackWindow)
 	nextPrice := lastBlock.Price
-	if recentUnits > vm.targetRangeUnits {
+	if recentUnits > targetRangeUnits {
 		nextPrice++
-	} else if recentUnits < vm.targetRangeUnits {
+	} else if recentUnits < targetRangeUnits {
 		elapsedWindows := uint64(secondsSinceLast/g.LookbackWindow) + 1 // account for current window being less
 		if nextPrice >= g.MinPrice && elapsedWindows < nextPrice-g.MinPrice {
 			nextPrice -= elapsedWindows
@@ -4,6 +4,7 @@
 package vm
 
 import (
+	"fmt"
 	"time"
 
 	log "github.com/inconshreveable/log15"
@@ -17,11 +18,19 @@ func (vm *VM) compactCall(r *chain.CompactRange) {
 	defer vm.ctx.Lock.Unlock()
 
 	start := time.Now()
+	label := fmt.Sprintf("%x", r.Start)
+	if vm.compactErrs == nil {
+		vm.compactErrs = map[string]error{}
+	}
 	if err := vm.db.Compact(r.Start, r.Limit); err != nil {
-		log.Error("unable to compact range", "start", r.Start, "stop", r.Limit)
+		log.Error("unable to compact range", "start", r.Start, "stop", r.Limit, "error", err)
+		vm.compactErrs[label] = err
 		return
 	}
-	log.Debug("compacted range", "start", r.Start, "stop", r.Limit, "t", time.Since(start))
+	delete(vm.compactErrs, label)
+	elapsed := time.Since(start)
+	vm.metrics.compactDuration.WithLabelValues(label).Observe(elapsed.Seconds())
+	log.Debug("compacted range", "start", r.Start, "stop", r.Limit, "t", elapsed)
 
 	// Make sure to update children or else won't be persisted
 	if err := vm.lastAccepted.SetChildrenDB(vm.db); err != nil {
@@ -30,6 +39,20 @@ func (vm *VM) compactCall(r *chain.CompactRange) {
 	}
 }
 
+// compactErr returns the error of the first range in [chain.CompactRanges]
+// whose most recent compaction failed (if any).
+//
+// The caller must hold [ctx.Lock].
+func (vm *VM) compactErr() error {
+	for _, r := range chain.CompactRanges {
+		label := fmt.Sprintf("%x", r.Start)
+		if err, ok := vm.compactErrs[label]; ok {
+			return fmt.Errorf("range %s: %w", label, err)
+		}
+	}
+	return nil
+}
+
 func (vm *VM) compact() {
 	log.Debug("starting compaction loops")
 	defer close(vm.doneCompact)
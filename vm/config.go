@@ -20,6 +20,17 @@ type Config struct {
 
 	MempoolSize       int `serialize:"true" json:"mempoolSize"`
 	ActivityCacheSize int `serialize:"true" json:"activityCacheSize"`
+
+	// Admin API (disabled by default). Enabling it requires [AdminAPIToken]
+	// and requests must include the header "Authorization: Bearer
+	// <AdminAPIToken>".
+	AdminAPIEnabled bool   `serialize:"true" json:"adminAPIEnabled"`
+	AdminAPIToken   string `serialize:"true" json:"adminAPIToken"`
+
+	// HealthCheck thresholds
+	HealthMaxBlockDelay     int64   `serialize:"true" json:"healthMaxBlockDelay"` // seconds
+	HealthMaxMempoolUsage   float64 `serialize:"true" json:"healthMaxMempoolUsage"`
+	HealthMaxPruningBacklog int     `serialize:"true" json:"healthMaxPruningBacklog"`
 }
 
 func (c *Config) SetDefaults() {
@@ -35,4 +46,8 @@ func (c *Config) SetDefaults() {
 
 	c.MempoolSize = 1024
 	c.ActivityCacheSize = 128
+
+	c.HealthMaxBlockDelay = 0 // 10 * [TargetBlockRate]
+	c.HealthMaxMempoolUsage = 0.9
+	c.HealthMaxPruningBacklog = 8192
 }
@@ -8,9 +8,15 @@ import (
 )
 
 var (
-	ErrNoPendingTx    = errors.New("no pending tx")
-	ErrTypedDataIsNil = errors.New("typed data is nil")
-	ErrInputIsNil     = errors.New("input is nil")
-	ErrInvalidEmptyTx = errors.New("invalid empty transaction")
-	ErrCorruption     = errors.New("corruption detected")
+	ErrNoPendingTx       = errors.New("no pending tx")
+	ErrTypedDataIsNil    = errors.New("typed data is nil")
+	ErrInputIsNil        = errors.New("input is nil")
+	ErrInvalidEmptyTx    = errors.New("invalid empty transaction")
+	ErrCorruption        = errors.New("corruption detected")
+	ErrUnhealthy         = errors.New("unhealthy")
+	ErrUnauthorized      = errors.New("unauthorized")
+	ErrMissingAdminToken = errors.New("admin API requires adminAPIToken")
+	ErrUnknownBuilder    = errors.New("unknown block builder")
+	ErrMissingSender     = errors.New("signature or sender is required")
+	ErrTooManyKeys       = errors.New("too many keys")
 )
@@ -0,0 +1,147 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package vm
+
+import (
+	"sort"
+	"time"
+
+	"github.com/ava-labs/spacesvm/chain"
+)
+
+const (
+	// feeHistoryBlocks is the number of accepted blocks considered when
+	// estimating fees.
+	feeHistoryBlocks = 20
+	// maxFeeHistoryBlocks is the most blocks that can be requested from
+	// [FeeHistory].
+	maxFeeHistoryBlocks = 256
+)
+
+// feeTargets are the inclusion speeds returned by [EstimateFee]. Faster
+// targets use a higher percentile of the prices paid by recently included
+// txs.
+var feeTargets = []struct {
+	blocks     uint64
+	percentile uint64
+}{
+	{1, 90},
+	{3, 60},
+	{10, 25},
+}
+
+type FeeHistoryEntry struct {
+	Height    uint64 `serialize:"true" json:"height"`
+	Timestamp int64  `serialize:"true" json:"timestamp"`
+	Price     uint64 `serialize:"true" json:"price"`
+	Cost      uint64 `serialize:"true" json:"cost"`
+	Txs       int    `serialize:"true" json:"txs"`
+	LoadUnits uint64 `serialize:"true" json:"loadUnits"`
+
+	// Prices paid by the txs included in the block (sorted ascending)
+	TxPrices []uint64 `serialize:"true" json:"txPrices"`
+}
+
+// FeeHistory returns up to [blocks] of the most recently accepted blocks,
+// newest first.
+func (vm *VM) FeeHistory(blocks int) ([]*FeeHistoryEntry, error) {
+	if blocks <= 0 || blocks > maxFeeHistoryBlocks {
+		blocks = maxFeeHistoryBlocks
+	}
+	history := make([]*FeeHistoryEntry, 0, blocks)
+	curr := vm.lastAccepted
+	for len(history) < blocks {
+		g := vm.Rules(curr.Tmstmp)
+		entry := &FeeHistoryEntry{
+			Height:    curr.Hght,
+			Timestamp: curr.Tmstmp,
+			Price:     curr.Price,
+			Cost:      curr.Cost,
+			Txs:       len(curr.Txs),
+			TxPrices:  make([]uint64, len(curr.Txs)),
+		}
+		for i, tx := range curr.Txs {
+			entry.LoadUnits += tx.LoadUnits(g)
+			entry.TxPrices[i] = tx.GetPrice()
+		}
+		sort.Slice(entry.TxPrices, func(i, j int) bool { return entry.TxPrices[i] < entry.TxPrices[j] })
+		history = append(history, entry)
+
+		if curr.Hght == 0 /* genesis */ {
+			break
+		}
+		prnt, err := vm.GetStatelessBlock(curr.Prnt)
+		if err != nil {
+			return nil, err
+		}
+		curr = prnt
+	}
+	return history, nil
+}
+
+type FeeEstimate struct {
+	// Blocks is the number of blocks the tx is expected to be included within
+	Blocks    uint64 `serialize:"true" json:"blocks"`
+	Price     uint64 `serialize:"true" json:"price"`
+	TotalCost uint64 `serialize:"true" json:"totalCost"`
+}
+
+// EstimateFee returns a price for [utx] at each of [feeTargets].
+//
+// Every block must collect a surplus of [Price * Cost] from its txs. The
+// next-block estimate assumes [utx] may need to pay this alone, while slower
+// targets assume it is shared by the average number of txs in recent blocks.
+func (vm *VM) EstimateFee(utx chain.UnsignedTransaction) ([]*FeeEstimate, error) {
+	parent, err := vm.GetStatelessBlock(vm.preferred)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().Unix()
+	ctx, err := vm.ExecutionContext(now, parent)
+	if err != nil {
+		return nil, err
+	}
+	history, err := vm.FeeHistory(feeHistoryBlocks)
+	if err != nil {
+		return nil, err
+	}
+	prices := []uint64{}
+	for _, entry := range history {
+		prices = append(prices, entry.TxPrices...)
+	}
+	sort.Slice(prices, func(i, j int) bool { return prices[i] < prices[j] })
+	avgTxs := uint64(len(prices)) / uint64(len(history))
+	if avgTxs == 0 {
+		avgTxs = 1
+	}
+
+	// Fee units are at least [BaseTxUnits], which [chain.Genesis.Verify] and
+	// [chain.Upgrades.Verify] ensure is positive (checked again so a bad
+	// config cannot panic the handler)
+	fu := utx.FeeUnits(vm.Rules(now))
+	if fu == 0 {
+		return nil, chain.ErrInvalidBaseTxUnits
+	}
+	requiredSurplus := ctx.NextPrice * ctx.NextCost
+	estimates := make([]*FeeEstimate, len(feeTargets))
+	for i, target := range feeTargets {
+		sharers := avgTxs
+		if target.blocks == 1 {
+			sharers = 1
+		}
+		share := requiredSurplus / sharers
+		price := ctx.NextPrice + (share+fu-1)/fu
+		if len(prices) > 0 {
+			if p := prices[uint64(len(prices)-1)*target.percentile/100]; p > price {
+				price = p
+			}
+		}
+		estimates[i] = &FeeEstimate{
+			Blocks:    target.blocks,
+			Price:     price,
+			TotalCost: price * fu,
+		}
+	}
+	return estimates, nil
+}
@@ -0,0 +1,90 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package vm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ava-labs/spacesvm/chain"
+)
+
+// defaultBlockDelayMultiplier is used to derive the maximum block delay from
+// [TargetBlockRate] when [HealthMaxBlockDelay] is not set.
+const defaultBlockDelayMultiplier = 10
+
+type Health struct {
+	Bootstrapped     bool     `json:"bootstrapped"`
+	LastAcceptedAge  string   `json:"lastAcceptedAge"`
+	MempoolSize      int      `json:"mempoolSize"`
+	MempoolUsage     float64  `json:"mempoolUsage"`
+	PruningBacklog   int      `json:"pruningBacklog"`
+	PruneError       string   `json:"pruneError,omitempty"`
+	CompactError     string   `json:"compactError,omitempty"`
+	UnhealthyReasons []string `json:"unhealthyReasons,omitempty"`
+}
+
+// implements "snowmanblock.ChainVM.commom.VM.health.Checkable"
+//
+// The engine holds [ctx.Lock] while calling this method.
+func (vm *VM) HealthCheck(ctx context.Context) (interface{}, error) {
+	h := &Health{Bootstrapped: vm.bootstrapped.Get()}
+	if !h.Bootstrapped {
+		h.UnhealthyReasons = append(h.UnhealthyReasons, "not bootstrapped")
+	}
+
+	// A chain with nothing to include is allowed to be idle, so a stale last
+	// accepted block is only a problem if txs are waiting.
+	now := time.Now()
+	age := now.Sub(time.Unix(vm.lastAccepted.Tmstmp, 0))
+	h.LastAcceptedAge = age.Round(time.Second).String()
+	h.MempoolSize = vm.mempool.Len()
+	maxDelay := time.Duration(vm.config.HealthMaxBlockDelay) * time.Second
+	if maxDelay == 0 {
+		g := vm.Rules(now.Unix())
+		maxDelay = time.Duration(g.TargetBlockRate*defaultBlockDelayMultiplier) * time.Second
+	}
+	if h.Bootstrapped && h.MempoolSize > 0 && age > maxDelay {
+		h.UnhealthyReasons = append(h.UnhealthyReasons,
+			fmt.Sprintf("no block accepted in %s with %d pending txs", h.LastAcceptedAge, h.MempoolSize),
+		)
+	}
+
+	if vm.config.MempoolSize > 0 {
+		h.MempoolUsage = float64(h.MempoolSize) / float64(vm.config.MempoolSize)
+		if h.MempoolUsage > vm.config.HealthMaxMempoolUsage {
+			h.UnhealthyReasons = append(h.UnhealthyReasons,
+				fmt.Sprintf("mempool is %.0f%% full", h.MempoolUsage*100),
+			)
+		}
+	}
+
+	// Only count one past the threshold to bound the cost of each check
+	backlog, err := chain.PruningBacklog(vm.db, vm.config.HealthMaxPruningBacklog+1)
+	if err != nil {
+		return h, err
+	}
+	h.PruningBacklog = backlog
+	if backlog > vm.config.HealthMaxPruningBacklog {
+		h.UnhealthyReasons = append(h.UnhealthyReasons,
+			fmt.Sprintf("more than %d spaces waiting to be pruned", vm.config.HealthMaxPruningBacklog),
+		)
+	}
+
+	if vm.pruneErr != nil {
+		h.PruneError = vm.pruneErr.Error()
+		h.UnhealthyReasons = append(h.UnhealthyReasons, "pruning failed")
+	}
+	if err := vm.compactErr(); err != nil {
+		h.CompactError = err.Error()
+		h.UnhealthyReasons = append(h.UnhealthyReasons, "compaction failed")
+	}
+
+	if len(h.UnhealthyReasons) > 0 {
+		return h, fmt.Errorf("%w: %s", ErrUnhealthy, strings.Join(h.UnhealthyReasons, "; "))
+	}
+	return h, nil
+}
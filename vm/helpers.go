@@ -63,7 +63,8 @@ func (vm *VM) SuggestedFee() (uint64, uint64, error) {
 		return 0, 0, fmt.Errorf("unexpected snowman.Block %T, expected *StatelessBlock", prnt)
 	}
 
-	ctx, err := vm.ExecutionContext(time.Now().Unix(), parent)
+	now := time.Now().Unix()
+	ctx, err := vm.ExecutionContext(now, parent)
 	if err != nil {
 		return 0, 0, err
 	}
@@ -71,7 +72,7 @@ func (vm *VM) SuggestedFee() (uint64, uint64, error) {
 	// Sort useful costs/prices
 	sort.Slice(ctx.Prices, func(i, j int) bool { return ctx.Prices[i] < ctx.Prices[j] })
 	pPrice := ctx.Prices[(len(ctx.Prices)-1)*feePercentile/100]
-	if g := vm.genesis; pPrice < g.MinPrice {
+	if g := vm.Rules(now); pPrice < g.MinPrice {
 		pPrice = g.MinPrice
 	}
 	sort.Slice(ctx.Costs, func(i, j int) bool { return ctx.Costs[i] < ctx.Costs[j] })
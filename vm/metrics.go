@@ -0,0 +1,67 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package vm
+
+import (
+	"github.com/ava-labs/avalanchego/utils/wrappers"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type metrics struct {
+	mempoolSize      prometheus.GaugeFunc
+	mempoolEvictions prometheus.CounterFunc
+
+	gossipTxs   prometheus.Counter
+	gossipBytes prometheus.Counter
+
+	pruneRemovals prometheus.Counter
+	pruneDuration prometheus.Histogram
+
+	compactDuration *prometheus.HistogramVec
+}
+
+func newMetrics(vm *VM, r prometheus.Registerer) (*metrics, error) {
+	m := &metrics{
+		mempoolSize: prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "mempool_size",
+			Help: "Number of txs in the mempool",
+		}, func() float64 { return float64(vm.mempool.Len()) }),
+		mempoolEvictions: prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "mempool_evictions",
+			Help: "Number of txs evicted from a full mempool",
+		}, func() float64 { return float64(vm.mempool.Evicted()) }),
+		gossipTxs: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "gossip_txs_sent",
+			Help: "Number of txs sent via AppGossip",
+		}),
+		gossipBytes: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "gossip_bytes_sent",
+			Help: "Number of bytes sent via AppGossip",
+		}),
+		pruneRemovals: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "prune_removals",
+			Help: "Number of expired raw spaces pruned",
+		}),
+		pruneDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name: "prune_duration",
+			Help: "Time spent pruning expired raw spaces (seconds)",
+		}),
+		compactDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "compact_duration",
+			Help:    "Time spent compacting a range (seconds)",
+			Buckets: prometheus.ExponentialBuckets(0.001, 4, 10),
+		}, []string{"range"}),
+	}
+	errs := wrappers.Errs{}
+	errs.Add(
+		r.Register(m.mempoolSize),
+		r.Register(m.mempoolEvictions),
+		r.Register(m.gossipTxs),
+		r.Register(m.gossipBytes),
+		r.Register(m.pruneRemovals),
+		r.Register(m.pruneDuration),
+		r.Register(m.compactDuration),
+	)
+	return m, errs.Err
+}
@@ -5,6 +5,7 @@ package vm
 
 import (
 	"context"
+	"time"
 
 	"github.com/ava-labs/avalanchego/cache"
 	"github.com/ava-labs/avalanchego/ids"
@@ -50,7 +51,8 @@ func (n *PushNetwork) sendTxs(txs []*chain.Transaction) error {
 		)
 		return err
 	}
-
+	n.vm.metrics.gossipTxs.Add(float64(len(txs)))
+	n.vm.metrics.gossipBytes.Add(float64(len(b)))
 	return nil
 }
 
@@ -83,15 +85,16 @@ func (n *PushNetwork) RegossipTxs() error {
 	}
 	txs := []*chain.Transaction{}
 	units := uint64(0)
+	g := n.vm.Rules(time.Now().Unix())
 	// Gossip at most the target units of a block at once
-	for n.vm.mempool.Len() > 0 && units < n.vm.genesis.TargetBlockSize {
+	for n.vm.mempool.Len() > 0 && units < g.TargetBlockSize {
 		tx, _ := n.vm.mempool.PopMax()
 
 		// Note: when regossiping, we force resend eventhough we may have done it
 		// recently.
 		n.gossipedTxs.Put(tx.ID(), nil)
 		txs = append(txs, tx)
-		units += tx.LoadUnits(n.vm.genesis)
+		units += tx.LoadUnits(g)
 	}
 
 	return n.sendTxs(txs)
@@ -17,17 +17,23 @@ func (vm *VM) pruneCall() bool {
 	vm.ctx.Lock.Lock()
 	defer vm.ctx.Lock.Unlock()
 
+	start := time.Now()
 	vdb := versiondb.New(vm.db)
 	defer vdb.Abort()
 	removals, err := chain.PruneNext(vdb, vm.config.PruneLimit)
 	if err != nil {
 		log.Warn("unable to prune next range", "error", err)
+		vm.pruneErr = err
 		return false
 	}
 	if err := vdb.Commit(); err != nil {
 		log.Warn("unable to commit pruning work", "error", err)
+		vm.pruneErr = err
 		return false
 	}
+	vm.pruneErr = nil
+	vm.metrics.pruneRemovals.Add(float64(removals))
+	vm.metrics.pruneDuration.Observe(time.Since(start).Seconds())
 	if err := vm.lastAccepted.SetChildrenDB(vm.db); err != nil {
 		log.Error("unable to update child databases of last accepted block", "error", err)
 	}
@@ -6,6 +6,7 @@ package vm
 import (
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ethereum/go-ethereum/common"
@@ -53,6 +54,17 @@ func (svc *PublicService) Genesis(_ *http.Request, _ *struct{}, reply *GenesisRe
 	return nil
 }
 
+type RulesReply struct {
+	Rules    *chain.Genesis  `serialize:"true" json:"rules"`
+	Upgrades *chain.Upgrades `serialize:"true" json:"upgrades"`
+}
+
+func (svc *PublicService) Rules(_ *http.Request, _ *struct{}, reply *RulesReply) (err error) {
+	reply.Rules = svc.vm.Rules(time.Now().Unix())
+	reply.Upgrades = svc.vm.upgrades
+	return nil
+}
+
 type IssueRawTxArgs struct {
 	Tx []byte `serialize:"true" json:"tx"`
 }
@@ -118,6 +130,43 @@ func (svc *PublicService) IssueTx(_ *http.Request, args *IssueTxArgs, reply *Iss
 	return fmt.Errorf("%v", errs)
 }
 
+type SimulateTxArgs struct {
+	TypedData *tdata.TypedData `serialize:"true" json:"typedData"`
+	// Signature may be omitted to simulate an unsigned tx from [Sender]
+	Signature hexutil.Bytes   `serialize:"true" json:"signature,omitempty"`
+	Sender    *common.Address `serialize:"true" json:"sender,omitempty"`
+}
+
+type SimulateTxReply struct {
+	TxID       ids.ID      `serialize:"true" json:"txId"`
+	Simulation *Simulation `serialize:"true" json:"simulation"`
+}
+
+func (svc *PublicService) SimulateTx(_ *http.Request, args *SimulateTxArgs, reply *SimulateTxReply) (err error) {
+	if args.TypedData == nil {
+		return ErrTypedDataIsNil
+	}
+	utx, err := chain.ParseTypedData(args.TypedData)
+	if err != nil {
+		return err
+	}
+	tx := chain.NewTx(utx, args.Signature[:])
+	switch {
+	case len(args.Signature) > 0:
+		err = tx.Init(svc.vm.genesis)
+	case args.Sender != nil:
+		err = tx.InitSimulated(*args.Sender)
+	default:
+		return ErrMissingSender
+	}
+	if err != nil {
+		return err
+	}
+	reply.TxID = tx.ID()
+	reply.Simulation, err = svc.vm.Simulate(tx)
+	return err
+}
+
 type HasTxArgs struct {
 	TxID ids.ID `serialize:"true" json:"txId"`
 }
@@ -154,6 +203,9 @@ type SuggestedFeeArgs struct {
 type SuggestedFeeReply struct {
 	TypedData *tdata.TypedData `serialize:"true" json:"typedData"`
 	TotalCost uint64           `serialize:"true" json:"totalCost"`
+	// ValidUntil is the unix time after which the block ID of [TypedData]
+	// may no longer be recent enough for the tx to be accepted
+	ValidUntil int64 `serialize:"true" json:"validUntil"`
 }
 
 func (svc *PublicService) SuggestedFee(
@@ -174,17 +226,19 @@ func (svc *PublicService) SuggestedFee(
 	if err != nil {
 		return err
 	}
-	g := svc.vm.genesis
+	g := svc.vm.Rules(time.Now().Unix())
 	fu := utx.FeeUnits(g)
 	price += cost / fu
 
 	// Update meta
-	utx.SetBlockID(svc.vm.lastAccepted.ID())
+	la := svc.vm.lastAccepted
+	utx.SetBlockID(la.ID())
 	utx.SetMagic(g.Magic)
 	utx.SetPrice(price)
 
 	reply.TypedData = utx.TypedData()
 	reply.TotalCost = fu * price
+	reply.ValidUntil = la.Tmstmp + g.LookbackWindow
 	return nil
 }
 
@@ -207,6 +261,39 @@ func (svc *PublicService) SuggestedRawFee(
 	return nil
 }
 
+type EstimateFeeArgs struct {
+	Input *chain.Input `serialize:"true" json:"input"`
+}
+
+type EstimateFeeReply struct {
+	Estimates []*FeeEstimate `serialize:"true" json:"estimates"`
+}
+
+func (svc *PublicService) EstimateFee(_ *http.Request, args *EstimateFeeArgs, reply *EstimateFeeReply) (err error) {
+	if args.Input == nil {
+		return ErrInputIsNil
+	}
+	utx, err := args.Input.Decode()
+	if err != nil {
+		return err
+	}
+	reply.Estimates, err = svc.vm.EstimateFee(utx)
+	return err
+}
+
+type FeeHistoryArgs struct {
+	Blocks int `serialize:"true" json:"blocks"`
+}
+
+type FeeHistoryReply struct {
+	History []*FeeHistoryEntry `serialize:"true" json:"history"`
+}
+
+func (svc *PublicService) FeeHistory(_ *http.Request, args *FeeHistoryArgs, reply *FeeHistoryReply) (err error) {
+	reply.History, err = svc.vm.FeeHistory(args.Blocks)
+	return err
+}
+
 type ClaimedArgs struct {
 	Space string `serialize:"true" json:"space"`
 }
@@ -258,6 +345,45 @@ func (svc *PublicService) Info(_ *http.Request, args *InfoArgs, reply *InfoReply
 	return nil
 }
 
+type ProjectSpaceArgs struct {
+	Space string               `serialize:"true" json:"space"`
+	Ops   []*chain.ProjectedOp `serialize:"true" json:"ops"`
+	// Target is the unix time the space should last until (optional)
+	Target uint64 `serialize:"true" json:"target"`
+}
+
+type ProjectSpaceReply struct {
+	Projection *chain.Projection `serialize:"true" json:"projection"`
+}
+
+func (svc *PublicService) ProjectSpace(_ *http.Request, args *ProjectSpaceArgs, reply *ProjectSpaceReply) (err error) {
+	if err := parser.CheckContents(args.Space); err != nil {
+		return err
+	}
+	now := time.Now().Unix()
+	reply.Projection, err = chain.ProjectSpace(
+		svc.vm.Rules(now), svc.vm.db, args.Space, uint64(now), args.Ops, args.Target,
+	)
+	return err
+}
+
+type SubscriptionArgs struct {
+	Space string `serialize:"true" json:"space"`
+}
+
+type SubscriptionReply struct {
+	Subscription *chain.Subscription `serialize:"true" json:"subscription"`
+	Exists       bool                `serialize:"true" json:"exists"`
+}
+
+func (svc *PublicService) Subscription(_ *http.Request, args *SubscriptionArgs, reply *SubscriptionReply) (err error) {
+	if err := parser.CheckContents(args.Space); err != nil {
+		return err
+	}
+	reply.Subscription, reply.Exists, err = chain.GetSubscription(svc.vm.db, []byte(args.Space))
+	return err
+}
+
 type ResolveArgs struct {
 	Path string `serialize:"true" json:"path"`
 }
@@ -297,6 +423,41 @@ func (svc *PublicService) Resolve(_ *http.Request, args *ResolveArgs, reply *Res
 	return nil
 }
 
+// maxHasKeys is the most keys that can be checked by [HasKeys].
+const maxHasKeys = 1024
+
+type HasKeysArgs struct {
+	Space string   `serialize:"true" json:"space"`
+	Keys  []string `serialize:"true" json:"keys"`
+}
+
+type HasKeysReply struct {
+	Exists []bool `serialize:"true" json:"exists"`
+}
+
+// HasKeys returns which of [Keys] have a value in [Space] (without fetching
+// the values).
+func (svc *PublicService) HasKeys(_ *http.Request, args *HasKeysArgs, reply *HasKeysReply) error {
+	if err := parser.CheckContents(args.Space); err != nil {
+		return err
+	}
+	if len(args.Keys) > maxHasKeys {
+		return fmt.Errorf("%w: %d > %d", ErrTooManyKeys, len(args.Keys), maxHasKeys)
+	}
+	reply.Exists = make([]bool, len(args.Keys))
+	for i, k := range args.Keys {
+		if err := parser.CheckContents(k); err != nil {
+			return err
+		}
+		has, err := chain.HasSpaceKey(svc.vm.db, []byte(args.Space), []byte(k))
+		if err != nil {
+			return err
+		}
+		reply.Exists[i] = has
+	}
+	return nil
+}
+
 type BalanceArgs struct {
 	Address common.Address `serialize:"true" json:"address"`
 }
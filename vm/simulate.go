@@ -0,0 +1,83 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package vm
+
+import (
+	"time"
+
+	"github.com/ava-labs/avalanchego/database/versiondb"
+
+	"github.com/ava-labs/spacesvm/chain"
+)
+
+type Simulation struct {
+	FeeUnits  uint64 `serialize:"true" json:"feeUnits"`
+	LoadUnits uint64 `serialize:"true" json:"loadUnits"`
+	Fee       uint64 `serialize:"true" json:"fee"`
+
+	// State of the sender and space after the tx is executed (or before it
+	// if it would fail)
+	Balance uint64           `serialize:"true" json:"balance"`
+	Info    *chain.SpaceInfo `serialize:"true" json:"info,omitempty"`
+
+	// Error is populated if the tx would fail
+	Error string `serialize:"true" json:"error,omitempty"`
+}
+
+// Simulate executes [tx] on top of the preferred block (like [Submit]) and
+// reports its effects without persisting them or adding [tx] to the
+// mempool. [tx] must already be initialized.
+func (vm *VM) Simulate(tx *chain.Transaction) (*Simulation, error) {
+	blk, err := vm.GetStatelessBlock(vm.preferred)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().Unix()
+	ctx, err := vm.ExecutionContext(now, blk)
+	if err != nil {
+		return nil, err
+	}
+	vdb := versiondb.New(vm.db)
+	defer vdb.Abort()
+
+	// Renew subscribed spaces and expire outdated spaces before checking
+	// validity
+	g := vm.Rules(now)
+	if err := chain.RenewNext(g, vdb, blk.Tmstmp, now, ctx.NextPrice); err != nil {
+		return nil, err
+	}
+	if err := chain.ExpireNext(vdb, blk.Tmstmp, now, true); err != nil {
+		return nil, err
+	}
+
+	sim := &Simulation{
+		FeeUnits:  tx.FeeUnits(g),
+		LoadUnits: tx.LoadUnits(g),
+	}
+	sim.Fee = sim.FeeUnits * tx.GetPrice()
+	dummy := chain.DummyBlock(now, tx)
+	// A failed tx may have written some state before failing, so it is
+	// executed on a separate db that is only read if it succeeds
+	state, txdb := vdb, versiondb.New(vdb)
+	if err := tx.Execute(g, txdb, dummy, ctx); err != nil {
+		sim.Error = err.Error()
+	} else {
+		state = txdb
+	}
+
+	sim.Balance, err = chain.GetBalance(state, tx.Sender())
+	if err != nil {
+		return nil, err
+	}
+	if space := tx.UnsignedTransaction.Activity().Space; len(space) > 0 {
+		info, exists, err := chain.GetSpaceInfo(state, []byte(space))
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			sim.Info = info
+		}
+	}
+	return sim, nil
+}
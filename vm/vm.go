@@ -8,10 +8,10 @@ import (
 	"context"
 	ejson "encoding/json"
 	"fmt"
-	"net/http"
 	"time"
 
 	"github.com/gorilla/rpc/v2"
+	"github.com/prometheus/client_golang/prometheus"
 
 	log "github.com/inconshreveable/log15"
 
@@ -36,6 +36,7 @@ import (
 const (
 	Name           = "spacesvm"
 	PublicEndpoint = "/public"
+	AdminEndpoint  = "/admin"
 )
 
 var (
@@ -48,13 +49,16 @@ type VM struct {
 	db          database.Database
 	config      Config
 	genesis     *chain.Genesis
+	upgrades    *chain.Upgrades
 	AirdropData []byte
 
 	bootstrapped utils.Atomic[bool]
 
-	mempool   *mempool.Mempool
-	appSender common.AppSender
-	network   *PushNetwork
+	mempool      *mempool.Mempool
+	metrics      *metrics
+	chainMetrics *chain.Metrics
+	appSender    common.AppSender
+	network      *PushNetwork
 
 	// cache block objects to optimize "GetBlockStateless"
 	// only put when a block is accepted
@@ -72,13 +76,19 @@ type VM struct {
 	preferred    ids.ID
 	lastAccepted *chain.StatelessBlock
 
+	// Handler of the root logger when the VM was initialized (wrapped by
+	// [AdminService.SetLogLevel])
+	logHandler log.Handler
+
+	// Most recent error seen by the prune loop (cleared on success) and by
+	// the compact loop for each range (cleared when that range succeeds)
+	pruneErr    error
+	compactErrs map[string]error
+
 	// Recent activity
 	activityCacheCursor uint64
 	activityCache       []*chain.Activity
 
-	// Execution checks
-	targetRangeUnits uint64
-
 	stop chan struct{}
 
 	builderStop chan struct{}
@@ -113,6 +123,10 @@ func (vm *VM) Initialize(
 			return fmt.Errorf("failed to unmarshal config %s: %w", string(configBytes), err)
 		}
 	}
+	if vm.config.AdminAPIEnabled && len(vm.config.AdminAPIToken) == 0 {
+		return ErrMissingAdminToken
+	}
+	vm.logHandler = log.Root().GetHandler()
 
 	vm.ctx = chainCtx
 	vm.db = dbManager.Current().Database
@@ -152,12 +166,36 @@ func (vm *VM) Initialize(
 		log.Error("genesis is invalid")
 		return err
 	}
-	targetUnitsPerSecond := vm.genesis.TargetBlockSize / uint64(vm.genesis.TargetBlockRate)
-	vm.targetRangeUnits = targetUnitsPerSecond * uint64(vm.genesis.LookbackWindow)
-	log.Debug("loaded genesis", "genesis", string(genesisBytes), "target range units", vm.targetRangeUnits)
+	log.Debug("loaded genesis", "genesis", string(genesisBytes))
+
+	// Parse upgrade schedule
+	vm.upgrades, err = chain.ParseUpgrades(upgradeBytes)
+	if err != nil {
+		log.Error("could not unmarshal upgrade bytes")
+		return err
+	}
+	if err := vm.upgrades.Verify(vm.genesis); err != nil {
+		log.Error("upgrades are invalid")
+		return err
+	}
+	log.Debug("loaded upgrades", "upgrades", len(vm.upgrades.Upgrades))
 
 	vm.mempool = mempool.New(vm.genesis, vm.config.MempoolSize)
 
+	// Register metrics
+	registry := prometheus.NewRegistry()
+	vm.metrics, err = newMetrics(vm, registry)
+	if err != nil {
+		return err
+	}
+	vm.chainMetrics, err = chain.NewMetrics(registry)
+	if err != nil {
+		return err
+	}
+	if err := chainCtx.Metrics.Register(registry); err != nil {
+		return err
+	}
+
 	if has { //nolint:nestif
 		blkID, err := chain.GetLastAccepted(vm.db)
 		if err != nil {
@@ -281,6 +319,18 @@ func (vm *VM) CreateHandlers(ctx context.Context) (map[string]*common.HTTPHandle
 		return nil, err
 	}
 	apis[PublicEndpoint] = public
+	if vm.config.AdminAPIEnabled {
+		if len(vm.config.AdminAPIToken) == 0 {
+			return nil, ErrMissingAdminToken
+		}
+		// Admin calls acquire [ctx.Lock] themselves when needed because some
+		// (like pruning) take the lock internally.
+		admin, err := newHandler(Name, &AdminService{vm: vm}, common.NoLock)
+		if err != nil {
+			return nil, err
+		}
+		apis[AdminEndpoint] = admin
+	}
 	return apis, nil
 }
 
@@ -308,11 +358,6 @@ func (vm *VM) AppResponse(ctx context.Context, nodeID ids.NodeID, requestID uint
 	return nil
 }
 
-// implements "snowmanblock.ChainVM.commom.VM.health.Checkable"
-func (vm *VM) HealthCheck(ctx context.Context) (interface{}, error) {
-	return http.StatusOK, nil
-}
-
 // implements "snowmanblock.ChainVM.commom.VM.validators.Connector"
 func (vm *VM) Connected(ctx context.Context, id ids.NodeID, nodeVersion *avagoversion.Application) error {
 	// no-op
@@ -411,7 +456,11 @@ func (vm *VM) Submit(txs ...*chain.Transaction) (errs []error) {
 	}
 	vdb := versiondb.New(vm.db)
 
-	// Expire outdated spaces before checking submission validity
+	// Renew subscribed spaces and expire outdated spaces before checking
+	// submission validity
+	if err := chain.RenewNext(vm.Rules(now), vdb, blk.Tmstmp, now, ctx.NextPrice); err != nil {
+		return []error{err}
+	}
 	if err := chain.ExpireNext(vdb, blk.Tmstmp, now, true); err != nil {
 		return []error{err}
 	}
@@ -431,14 +480,15 @@ func (vm *VM) Submit(txs ...*chain.Transaction) (errs []error) {
 }
 
 func (vm *VM) submit(tx *chain.Transaction, db database.Database, blkTime int64, ctx *chain.Context) error {
-	if err := tx.Init(vm.genesis); err != nil {
+	g := vm.Rules(blkTime)
+	if err := tx.Init(g); err != nil {
 		return err
 	}
-	if err := tx.ExecuteBase(vm.genesis); err != nil {
+	if err := tx.ExecuteBase(g); err != nil {
 		return err
 	}
 	dummy := chain.DummyBlock(blkTime, tx)
-	if err := tx.Execute(vm.genesis, db, dummy, ctx); err != nil {
+	if err := tx.Execute(g, db, dummy, ctx); err != nil {
 		return err
 	}
 	vm.mempool.Add(tx)
@@ -4,12 +4,29 @@
 package vm
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http/httptest"
 	"reflect"
 	"testing"
+	"time"
 
+	avago_metrics "github.com/ava-labs/avalanchego/api/metrics"
 	"github.com/ava-labs/avalanchego/cache"
+	"github.com/ava-labs/avalanchego/database/manager"
+	"github.com/ava-labs/avalanchego/database/memdb"
 	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow"
+	"github.com/ava-labs/avalanchego/snow/consensus/snowman"
+	"github.com/ava-labs/avalanchego/snow/engine/common"
+	avago_version "github.com/ava-labs/avalanchego/version"
+	"github.com/ethereum/go-ethereum/crypto"
+	log "github.com/inconshreveable/log15"
+
 	"github.com/ava-labs/spacesvm/chain"
+	"github.com/ava-labs/spacesvm/mempool"
 )
 
 func TestBlockCache(t *testing.T) {
@@ -44,3 +61,209 @@ func TestBlockCache(t *testing.T) {
 		t.Fatalf("block expected %+v, got %+v", blk, blk2)
 	}
 }
+
+func TestHealthCheck(t *testing.T) {
+	g := chain.DefaultGenesis()
+	vm := VM{
+		db:      memdb.New(),
+		genesis: g,
+		mempool: mempool.New(g, 16),
+		lastAccepted: &chain.StatelessBlock{
+			StatefulBlock: &chain.StatefulBlock{Tmstmp: time.Now().Unix()},
+		},
+	}
+	vm.config.SetDefaults()
+	vm.config.MempoolSize = 16
+
+	// not bootstrapped
+	if _, err := vm.HealthCheck(context.Background()); !errors.Is(err, ErrUnhealthy) {
+		t.Fatalf("expected %v, got %v", ErrUnhealthy, err)
+	}
+	vm.bootstrapped.Set(true)
+	if _, err := vm.HealthCheck(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// pruning failed
+	vm.pruneErr = errors.New("boom")
+	if _, err := vm.HealthCheck(context.Background()); !errors.Is(err, ErrUnhealthy) {
+		t.Fatalf("expected %v, got %v", ErrUnhealthy, err)
+	}
+	vm.pruneErr = nil
+
+	// compaction of one range failed (others succeeding does not clear it)
+	r := chain.CompactRanges[0]
+	vm.compactErrs = map[string]error{fmt.Sprintf("%x", r.Start): errors.New("boom")}
+	h, err := vm.HealthCheck(context.Background())
+	if !errors.Is(err, ErrUnhealthy) {
+		t.Fatalf("expected %v, got %v", ErrUnhealthy, err)
+	}
+	if len(h.(*Health).CompactError) == 0 {
+		t.Fatal("expected compact error")
+	}
+	vm.compactErrs = nil
+
+	// pruning backlog
+	if err := vm.db.Put(chain.PrefixPruningKey(1, ids.GenerateTestShortID()), nil); err != nil {
+		t.Fatal(err)
+	}
+	vm.config.HealthMaxPruningBacklog = 0
+	h, err = vm.HealthCheck(context.Background())
+	if !errors.Is(err, ErrUnhealthy) {
+		t.Fatalf("expected %v, got %v", ErrUnhealthy, err)
+	}
+	if backlog := h.(*Health).PruningBacklog; backlog != 1 {
+		t.Fatalf("expected backlog of 1, got %d", backlog)
+	}
+}
+
+func TestAdminAuthorize(t *testing.T) {
+	svc := &AdminService{vm: &VM{}}
+	r := httptest.NewRequest("POST", AdminEndpoint, nil)
+	if err := svc.authorize(r); !errors.Is(err, ErrMissingAdminToken) {
+		t.Fatalf("expected %v, got %v", ErrMissingAdminToken, err)
+	}
+
+	svc.vm.config.AdminAPIToken = "secret"
+	if err := svc.authorize(r); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("expected %v, got %v", ErrUnauthorized, err)
+	}
+	r.Header.Set("Authorization", "Bearer wrong")
+	if err := svc.authorize(r); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("expected %v, got %v", ErrUnauthorized, err)
+	}
+	r.Header.Set("Authorization", "Bearer secret")
+	if err := svc.authorize(r); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAdminSetLogLevel(t *testing.T) {
+	root := log.Root().GetHandler()
+	defer log.Root().SetHandler(root)
+
+	var logged []string
+	h := log.FuncHandler(func(r *log.Record) error {
+		logged = append(logged, r.Msg)
+		return nil
+	})
+	svc := &AdminService{vm: &VM{logHandler: h}}
+	svc.vm.config.AdminAPIToken = "secret"
+	r := httptest.NewRequest("POST", AdminEndpoint, nil)
+	r.Header.Set("Authorization", "Bearer secret")
+	if err := svc.SetLogLevel(r, &SetLogLevelArgs{Level: "error"}, &SuccessReply{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The original handler still receives records at or above the new level
+	logged = nil
+	log.Info("filtered")
+	log.Error("kept")
+	if !reflect.DeepEqual(logged, []string{"kept"}) {
+		t.Fatalf("expected only kept record, got %v", logged)
+	}
+}
+
+func TestBuildBlockRetry(t *testing.T) {
+	priv, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := chain.DefaultGenesis()
+	g.Magic = 1
+	// Keep the block cost above zero for the duration of the test
+	g.TargetBlockRate = 60
+	g.CustomAllocation = []*chain.CustomAllocation{
+		{Address: crypto.PubkeyToAddress(priv.PublicKey), Balance: 10_000_000},
+	}
+	genesis, err := json.Marshal(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	vm := &VM{}
+	if err := vm.Initialize(
+		ctx,
+		&snow.Context{Metrics: avago_metrics.NewOptionalGatherer()},
+		manager.NewMemDB(avago_version.CurrentDatabase),
+		genesis,
+		nil,
+		nil,
+		make(chan common.Message, 1),
+		nil,
+		nil,
+	); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := vm.Shutdown(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	claim := func(space string, price uint64) *chain.Transaction {
+		utx := &chain.ClaimTx{BaseTx: &chain.BaseTx{}, Space: space}
+		utx.SetBlockID(vm.preferred)
+		utx.SetMagic(g.Magic)
+		utx.SetPrice(price)
+		dh, err := chain.DigestHash(utx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig, err := chain.Sign(dh, priv)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tx := chain.NewTx(utx, sig)
+		if err := tx.Init(g); err != nil {
+			t.Fatal(err)
+		}
+		if errs := vm.Submit(tx); len(errs) > 0 {
+			t.Fatal(errs[0])
+		}
+		return tx
+	}
+	build := func() (snowman.Block, error) {
+		blk, err := vm.BuildBlock(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if err := blk.Verify(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if err := blk.Accept(ctx); err != nil {
+			t.Fatal(err)
+		}
+		return blk, vm.SetPreference(ctx, blk.ID())
+	}
+
+	// The parent of the first block is old, so it has no cost
+	claim("first", g.MinPrice)
+	if _, err := build(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A tx without surplus fee can't pay for a block right after its parent
+	// but stays in the mempool
+	low := claim("second", g.MinPrice)
+	if _, err := build(); !errors.Is(err, chain.ErrInsufficientSurplus) {
+		t.Fatalf("expected %v, got %v", chain.ErrInsufficientSurplus, err)
+	}
+	if !vm.mempool.Has(low.ID()) {
+		t.Fatal("tx was not retained after failed block verification")
+	}
+
+	// It is included once another tx pays enough surplus fee
+	claim("third", 1_000)
+	blk, err := build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if txs := blk.(*chain.StatelessBlock).Txs; len(txs) != 2 {
+		t.Fatalf("expected 2 txs, got %d", len(txs))
+	}
+	if vm.mempool.Len() != 0 {
+		t.Fatalf("expected empty mempool, got %d txs", vm.mempool.Len())
+	}
+}